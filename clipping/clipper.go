@@ -0,0 +1,186 @@
+package clipping
+
+// Clipper is a segment clipping algorithm. Clip returns the part of seg
+// visible in r, false if there is none, and the number of steps the
+// algorithm took, so that different clippers can be compared on the same
+// input. What a step is depends on the algorithm.
+type Clipper interface {
+	Clip(seg Segment, r Rect) (clipped Segment, ok bool, steps int)
+	String() string
+}
+
+// Clippers lists the available algorithms in the order the LAB_5 app
+// cycles through them.
+var Clippers = []Clipper{Midpoint{}, IntegerMidpoint{}, CohenSutherland{}, LiangBarsky{}, CyrusBeck{}}
+
+// CohenSutherland moves an invisible end to the border it is outside of
+// until the segment is trivially accepted or rejected. Its steps are the
+// intersections with the borders.
+type CohenSutherland struct{}
+
+func (CohenSutherland) String() string {
+	return "Cohen-Sutherland"
+}
+
+func (CohenSutherland) Clip(seg Segment, r Rect) (Segment, bool, int) {
+	steps := 0
+	for {
+		first, second := r.Code(seg[0]), r.Code(seg[1])
+		if first|second == 0 {
+			return seg, true, steps
+		}
+		if first&second != 0 {
+			return Segment{}, false, steps
+		}
+		i, code := 0, first
+		if first == 0 {
+			i, code = 1, second
+		}
+		p, q := seg[i], seg[1-i]
+		switch {
+		case code&LeftSide != 0:
+			p = Point{r.Left, p.Y + (q.Y-p.Y)*(r.Left-p.X)/(q.X-p.X)}
+		case code&RightSide != 0:
+			p = Point{r.Right, p.Y + (q.Y-p.Y)*(r.Right-p.X)/(q.X-p.X)}
+		case code&TopSide != 0:
+			p = Point{p.X + (q.X-p.X)*(r.Top-p.Y)/(q.Y-p.Y), r.Top}
+		case code&BottomSide != 0:
+			p = Point{p.X + (q.X-p.X)*(r.Bottom-p.Y)/(q.Y-p.Y), r.Bottom}
+		}
+		seg[i] = p
+		steps++
+	}
+}
+
+// LiangBarsky narrows the parameter range of the segment by the four
+// borders one after another. Its steps are the borders tested.
+type LiangBarsky struct{}
+
+func (LiangBarsky) String() string {
+	return "Liang-Barsky"
+}
+
+func (LiangBarsky) Clip(seg Segment, r Rect) (Segment, bool, int) {
+	dx, dy := seg[1].X-seg[0].X, seg[1].Y-seg[0].Y
+	p := [4]float64{-dx, dx, -dy, dy}
+	q := [4]float64{seg[0].X - r.Left, r.Right - seg[0].X, seg[0].Y - r.Top, r.Bottom - seg[0].Y}
+	tStart, tEnd := 0.0, 1.0
+	for i := range p {
+		if p[i] == 0 {
+			//параллельно границе
+			if q[i] < 0 {
+				return Segment{}, false, i + 1
+			}
+			continue
+		}
+		t := q[i] / p[i]
+		if p[i] < 0 && t > tStart {
+			tStart = t
+		}
+		if p[i] > 0 && t < tEnd {
+			tEnd = t
+		}
+		if tStart > tEnd {
+			return Segment{}, false, i + 1
+		}
+	}
+	return Segment{seg.at(tStart), seg.at(tEnd)}, true, len(p)
+}
+
+// CyrusBeck clips by the window as by a convex polygon, see ClipConvex.
+type CyrusBeck struct{}
+
+func (CyrusBeck) String() string {
+	return "Cyrus-Beck"
+}
+
+func (CyrusBeck) Clip(seg Segment, r Rect) (Segment, bool, int) {
+	return ClipConvex(seg, r.Polygon())
+}
+
+// Polygon returns the corners of r.
+func (r Rect) Polygon() []Point {
+	return []Point{{r.Left, r.Top}, {r.Right, r.Top}, {r.Right, r.Bottom}, {r.Left, r.Bottom}}
+}
+
+func (seg Segment) at(t float64) Point {
+	if t == 1 {
+		return seg[1]
+	}
+	return Point{seg[0].X + (seg[1].X-seg[0].X)*t, seg[0].Y + (seg[1].Y-seg[0].Y)*t}
+}
+
+func dot(a, b Point) float64 {
+	return a.X*b.X + a.Y*b.Y
+}
+
+func sub(a, b Point) Point {
+	return Point{a.X - b.X, a.Y - b.Y}
+}
+
+// normals returns the inner normals of the edges of the convex polygon: the
+// i-th one belongs to the edge from the i-th vertex to the next. A polygon
+// of zero area has no inside, and normals returns nil for it.
+func normals(polygon []Point) []Point {
+	area := 0.0
+	for i, p := range polygon {
+		next := polygon[(i+1)%len(polygon)]
+		area += p.X*next.Y - next.X*p.Y
+	}
+	if area == 0 {
+		return nil
+	}
+	normals := make([]Point, len(polygon))
+	for i, p := range polygon {
+		edge := sub(polygon[(i+1)%len(polygon)], p)
+		normals[i] = Point{-edge.Y, edge.X}
+		if area < 0 {
+			normals[i] = Point{edge.Y, -edge.X}
+		}
+	}
+	return normals
+}
+
+// ClipConvex returns the part of seg inside the convex polygon using the
+// Cyrus–Beck algorithm. The polygon may go either way round; nothing is
+// inside a polygon of zero area. Its steps are the edges tested.
+func ClipConvex(seg Segment, polygon []Point) (Segment, bool, int) {
+	tStart, tEnd, ok, steps := convexRange(seg, polygon)
+	if !ok {
+		return Segment{}, false, steps
+	}
+	return Segment{seg.at(tStart), seg.at(tEnd)}, true, steps
+}
+
+// convexRange is ClipConvex returning the range of the parameter of seg
+// inside the polygon.
+func convexRange(seg Segment, polygon []Point) (float64, float64, bool, int) {
+	inner := normals(polygon)
+	if inner == nil {
+		return 0, 0, false, 0
+	}
+	d := sub(seg[1], seg[0])
+	tStart, tEnd := 0.0, 1.0
+	for i, n := range inner {
+		den := dot(d, n)
+		num := dot(n, sub(seg[0], polygon[i]))
+		if den == 0 {
+			//параллельно грани и при этом снаружи
+			if num < 0 {
+				return 0, 0, false, i + 1
+			}
+			continue
+		}
+		t := -num / den
+		if den > 0 && t > tStart {
+			tStart = t
+		}
+		if den < 0 && t < tEnd {
+			tEnd = t
+		}
+		if tStart > tEnd {
+			return 0, 0, false, i + 1
+		}
+	}
+	return tStart, tEnd, true, len(polygon)
+}
@@ -0,0 +1,190 @@
+package clipping
+
+import "math"
+
+// Accuracy is the length below which midpoint subdivision stops halving a
+// segment.
+const Accuracy = math.Sqrt2
+
+// Point is a point in window coordinates: y grows downwards.
+type Point struct {
+	X, Y float64
+}
+
+// Segment is a line segment between two points.
+type Segment [2]Point
+
+// Rect is an axis-aligned clip window. Top is the smaller y.
+type Rect struct {
+	Left, Top, Right, Bottom float64
+}
+
+// Normalized returns r with Left ≤ Right and Top ≤ Bottom.
+func (r Rect) Normalized() Rect {
+	if r.Left > r.Right {
+		r.Left, r.Right = r.Right, r.Left
+	}
+	if r.Top > r.Bottom {
+		r.Top, r.Bottom = r.Bottom, r.Top
+	}
+	return r
+}
+
+// Outcode bits of a point lying outside of a clip window.
+const (
+	LeftSide = 1 << iota
+	TopSide
+	RightSide
+	BottomSide
+)
+
+// Code returns the outcode of p: the sides of r that p lies outside of.
+func (r Rect) Code(p Point) int {
+	code := 0
+	if p.X < r.Left {
+		code |= LeftSide
+	}
+	if p.Y < r.Top {
+		code |= TopSide
+	}
+	if p.X > r.Right {
+		code |= RightSide
+	}
+	if p.Y > r.Bottom {
+		code |= BottomSide
+	}
+	return code
+}
+
+// ClipSegment returns the part of seg visible in r, found by midpoint
+// subdivision. Every invisible end is moved towards the other one by halving
+// until it is closer than Accuracy to the border, so the result lies in r
+// and may fall short of its border by less than Accuracy. Only a segment
+// that just touches a corner of r keeps its ends outside, by no more than
+// touching. ok is false when seg is invisible.
+func ClipSegment(seg Segment, r Rect) (clipped Segment, ok bool) {
+	clipped, ok, _ = Midpoint{}.Clip(seg, r)
+	return clipped, ok
+}
+
+// Midpoint is the midpoint subdivision clipper of ClipSegment. Its steps
+// are the halvings.
+type Midpoint struct{}
+
+func (Midpoint) String() string {
+	return "midpoint"
+}
+
+func (Midpoint) Clip(seg Segment, r Rect) (Segment, bool, int) {
+	return midpoint(seg, r, nil)
+}
+
+// Step is one halving of midpoint subdivision.
+type Step struct {
+	// Count is the pass over the segment ends, starting from 1: every
+	// pass moves one invisible end and swaps the ends. On the third pass
+	// the segment left with its ends on different sides is halved to see
+	// whether it passes through the window.
+	Count    int
+	Segment  Segment
+	Midpoint Point
+	// Codes are the outcodes of both ends of Segment and of Midpoint.
+	Codes [3]int
+}
+
+// MidpointTrace is ClipSegment that also returns every halving it made.
+func MidpointTrace(seg Segment, r Rect) (Segment, bool, []Step) {
+	trace := []Step{}
+	clipped, ok, _ := midpoint(seg, r, &trace)
+	return clipped, ok, trace
+}
+
+func midpoint(seg Segment, r Rect, trace *[]Step) (Segment, bool, int) {
+	steps := 0
+	//каждый проход обрабатывает второй конец и переставляет концы местами
+	for pass := 0; ; pass++ {
+		first, second := r.Code(seg[0]), r.Code(seg[1])
+		if first|second == 0 {
+			//после нечётного числа проходов концы переставлены
+			if pass%2 == 1 {
+				seg[0], seg[1] = seg[1], seg[0]
+			}
+			return seg, true, steps
+		}
+		if first&second != 0 {
+			return Segment{}, false, steps
+		}
+		if pass == 2 {
+			ok, n := visible(seg, r, pass+1, trace)
+			return seg, ok, steps + n
+		}
+		if second != 0 {
+			var n int
+			seg[1], n = approach(seg[0], seg[1], second, r, pass+1, trace)
+			steps += n
+		}
+		seg[0], seg[1] = seg[1], seg[0]
+	}
+}
+
+// touching is the length of a piece of a segment that visible takes as
+// touching the clip window.
+const touching = 1e-9
+
+// visible decides whether seg, whose ends lie outside of r on different
+// sides, passes through r by halving it until a piece has a point inside r
+// or every piece is trivially invisible. It returns the number of halvings
+// too, and appends them to trace unless it is nil. Without it a segment
+// passing by a corner of r would be accepted.
+func visible(seg Segment, r Rect, count int, trace *[]Step) (bool, int) {
+	steps := 0
+	pieces := []Segment{seg}
+	for len(pieces) > 0 {
+		piece := pieces[len(pieces)-1]
+		pieces = pieces[:len(pieces)-1]
+		first, second := r.Code(piece[0]), r.Code(piece[1])
+		if first&second != 0 {
+			continue
+		}
+		if first == 0 || second == 0 || math.Hypot(piece[0].X-piece[1].X, piece[0].Y-piece[1].Y) < touching {
+			return true, steps
+		}
+		midpoint := Point{(piece[0].X + piece[1].X) / 2, (piece[0].Y + piece[1].Y) / 2}
+		if trace != nil {
+			*trace = append(*trace, Step{count, piece, midpoint, [3]int{first, second, r.Code(midpoint)}})
+		}
+		steps++
+		pieces = append(pieces, Segment{piece[0], midpoint}, Segment{midpoint, piece[1]})
+	}
+	return false, steps
+}
+
+// approach moves the invisible end b with outcode code towards a and
+// returns the last point of the halving that is not outside on the sides of
+// b, along with the number of halvings. Halving goes on past Accuracy while
+// that point is outside of r on other sides, since the part of the segment
+// in r can only lie beyond it: a segment cutting a corner of r by less
+// than Accuracy must not be rejected. If the point never gets into r, the
+// last point outside on the sides of b is returned instead, so that visible
+// can tell whether the segment touches a corner. The halvings are appended
+// to trace unless it is nil.
+func approach(a, b Point, code int, r Rect, count int, trace *[]Step) (Point, int) {
+	steps := 0
+	for d := math.Hypot(a.X-b.X, a.Y-b.Y); d > Accuracy || r.Code(a) != 0 && d > touching; d = math.Hypot(a.X-b.X, a.Y-b.Y) {
+		midpoint := Point{(a.X + b.X) / 2, (a.Y + b.Y) / 2}
+		if trace != nil {
+			codes := [3]int{r.Code(a), r.Code(b), r.Code(midpoint)}
+			*trace = append(*trace, Step{count, Segment{a, b}, midpoint, codes})
+		}
+		if r.Code(midpoint)&code != 0 {
+			b = midpoint
+		} else {
+			a = midpoint
+		}
+		steps++
+	}
+	if r.Code(a) != 0 {
+		return b, steps
+	}
+	return a, steps
+}
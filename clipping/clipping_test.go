@@ -0,0 +1,210 @@
+package clipping
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var window = Rect{Left: 0, Top: 0, Right: 100, Bottom: 100}
+
+func distance(a, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+func TestClipSegment(t *testing.T) {
+	tests := []struct {
+		name string
+		seg  Segment
+		want Segment
+		ok   bool
+	}{
+		{"inside", Segment{{10, 10}, {90, 50}}, Segment{{10, 10}, {90, 50}}, true},
+		{"outside on one side", Segment{{-50, -50}, {-10, 120}}, Segment{}, false},
+		{"outside above", Segment{{-20, -1}, {130, -5}}, Segment{}, false},
+		{"one border", Segment{{50, 50}, {150, 50}}, Segment{{50, 50}, {100, 50}}, true},
+		{"one border from outside", Segment{{30, -40}, {60, 20}}, Segment{{50, 0}, {60, 20}}, true},
+		{"two borders", Segment{{-50, 50}, {150, 70}}, Segment{{0, 55}, {100, 65}}, true},
+		{"two borders across", Segment{{20, -10}, {80, 110}}, Segment{{25, 0}, {75, 100}}, true},
+		{"through a corner", Segment{{-10, 20}, {20, -10}}, Segment{{0, 10}, {10, 0}}, true},
+		{"grazing a corner", Segment{{-10, 10.5}, {10.5, -10}}, Segment{{0, 0.5}, {0.5, 0}}, true},
+		// Segments passing by a corner have outcodes of the ends with no
+		// common side, so they cannot be rejected trivially.
+		{"by a corner", Segment{{-20, 10}, {10, -20}}, Segment{}, false},
+		{"close by a corner", Segment{{-10, 9.9}, {9.9, -10}}, Segment{}, false},
+		{"close by a corner, askew", Segment{{-0.20118160959377818, 0.023902244843484577}, {13.745844764325225, -16.3499955979398}}, Segment{}, false},
+		{"close by the bottom right corner", Segment{{90, 110.05}, {110.05, 90}}, Segment{}, false},
+	}
+	for _, test := range tests {
+		got, ok := ClipSegment(test.seg, window)
+		if ok != test.ok {
+			t.Errorf("%s: ok = %v, want %v", test.name, ok, test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		for i := range got {
+			if code := window.Code(got[i]); code != 0 {
+				t.Errorf("%s: end %d is %v, outside of the window with outcode %d", test.name, i, got[i], code)
+			}
+			if d := distance(got[i], test.want[i]); d > Accuracy {
+				t.Errorf("%s: end %d is %v, %.3f away from %v", test.name, i, got[i], d, test.want[i])
+			}
+		}
+	}
+}
+
+func TestIntegerMidpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		seg  Segment
+		want Segment
+		ok   bool
+	}{
+		{"inside", Segment{{10, 10}, {90, 50}}, Segment{{10, 10}, {90, 50}}, true},
+		{"one border", Segment{{50.3, 50}, {150, 50}}, Segment{{50.3, 50}, {100, 50}}, true},
+		{"two borders", Segment{{-50, 50}, {150, 70}}, Segment{{0, 55}, {100, 65}}, true},
+		{"subpixel ends", Segment{{-3.4, 46.97}, {20.2, 0.6}}, Segment{{0, 40}, {20.2, 0.6}}, true},
+		{"by a corner", Segment{{-20, 10}, {10, -20}}, Segment{}, false},
+		{"close by a corner", Segment{{-10, 9.9}, {9.9, -10}}, Segment{}, false},
+		{"close by the bottom right corner", Segment{{90, 110.05}, {110.05, 90}}, Segment{}, false},
+	}
+	for _, test := range tests {
+		got, ok, _ := IntegerMidpoint{}.Clip(test.seg, window)
+		if ok != test.ok {
+			t.Errorf("%s: ok = %v, want %v", test.name, ok, test.ok)
+			continue
+		}
+		if ok && got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	fractional := Rect{Left: 0.4, Top: 0.4, Right: 99.6, Bottom: 99.6}
+	want := Segment{{50, 50}, {99, 50}}
+	if got, ok, _ := (IntegerMidpoint{}).Clip(Segment{{50, 50}, {150, 50}}, fractional); !ok || got != want {
+		t.Errorf("fractional window: got %v, %v, want %v", got, ok, want)
+	}
+
+	//решение совпадает с ClipSegment, концы не дальше полупикселя по каждой оси
+	rnd := rand.New(rand.NewSource(1))
+	coord := func() float64 { return rnd.Float64()*200 - 50 }
+	for i := 0; i < 10000; i++ {
+		seg := Segment{{coord(), coord()}, {coord(), coord()}}
+		want, wantOK := ClipSegment(seg, window)
+		got, ok, _ := IntegerMidpoint{}.Clip(seg, window)
+		if ok != wantOK {
+			t.Fatalf("%v: ok = %v, want %v", seg, ok, wantOK)
+		}
+		for j := range got {
+			if code := window.Code(got[j]); ok && code != 0 {
+				t.Fatalf("%v: end %d is %v, outside of the window with outcode %d", seg, j, got[j], code)
+			}
+			if ok && distance(got[j], want[j]) > math.Sqrt2/2+Accuracy {
+				t.Fatalf("%v: end %d is %v, %.3f away from %v", seg, j, got[j], distance(got[j], want[j]), want[j])
+			}
+		}
+	}
+}
+
+func TestZeroAreaWindow(t *testing.T) {
+	seg := Segment{{0, 0}, {100, 100}}
+	for _, polygon := range [][]Point{
+		Rect{50, 50, 50, 50}.Polygon(),
+		Rect{20, 50, 80, 50}.Polygon(),
+		{{0, 0}, {50, 50}, {100, 100}},
+	} {
+		if got, ok, _ := ClipConvex(seg, polygon); ok {
+			t.Errorf("%v: ClipConvex gives %v, want nothing", polygon, got)
+		}
+		if got := ClipConvexOutside(seg, polygon); len(got) != 1 || got[0] != seg {
+			t.Errorf("%v: ClipConvexOutside gives %v, want %v", polygon, got, seg)
+		}
+		if got := SutherlandHodgman([][]Point{Rect{0, 0, 100, 100}.Polygon()}, polygon); len(got) != 0 {
+			t.Errorf("%v: SutherlandHodgman gives %v, want nothing", polygon, got)
+		}
+	}
+}
+
+func TestExactClippers(t *testing.T) {
+	tests := []struct {
+		name string
+		seg  Segment
+		want Segment
+		ok   bool
+	}{
+		{"inside", Segment{{10, 10}, {90, 50}}, Segment{{10, 10}, {90, 50}}, true},
+		{"outside on one side", Segment{{-50, -50}, {-10, 120}}, Segment{}, false},
+		{"outside below", Segment{{-20, 101}, {130, 105}}, Segment{}, false},
+		{"one border", Segment{{50, 50}, {150, 50}}, Segment{{50, 50}, {100, 50}}, true},
+		{"two borders", Segment{{-50, 50}, {150, 70}}, Segment{{0, 55}, {100, 65}}, true},
+		{"two borders across", Segment{{20, -10}, {80, 110}}, Segment{{25, 0}, {75, 100}}, true},
+		{"through a corner", Segment{{-10, 20}, {20, -10}}, Segment{{0, 10}, {10, 0}}, true},
+		{"grazing a corner", Segment{{-10, 10}, {10, -10}}, Segment{{0, 0}, {0, 0}}, true},
+		{"by a corner", Segment{{-20, 10}, {10, -20}}, Segment{}, false},
+		{"on the border", Segment{{-10, 0}, {50, 0}}, Segment{{0, 0}, {50, 0}}, true},
+	}
+	for _, clipper := range []Clipper{CohenSutherland{}, LiangBarsky{}, CyrusBeck{}} {
+		for _, test := range tests {
+			got, ok, _ := clipper.Clip(test.seg, window)
+			if ok != test.ok {
+				t.Errorf("%v, %s: ok = %v, want %v", clipper, test.name, ok, test.ok)
+				continue
+			}
+			if ok && (distance(got[0], test.want[0]) > 1e-9 || distance(got[1], test.want[1]) > 1e-9) {
+				t.Errorf("%v, %s: got %v, want %v", clipper, test.name, got, test.want)
+			}
+		}
+	}
+}
+
+func TestClipConvex(t *testing.T) {
+	// Both ways round.
+	triangles := [][]Point{{{0, 0}, {100, 0}, {0, 100}}, {{0, 100}, {100, 0}, {0, 0}}}
+	hexagon := []Point{{30, 0}, {70, 0}, {100, 50}, {70, 100}, {30, 100}, {0, 50}}
+	tests := []struct {
+		name    string
+		seg     Segment
+		polygon []Point
+		want    Segment
+		ok      bool
+	}{
+		{"inside", Segment{{10, 10}, {20, 30}}, triangles[0], Segment{{10, 10}, {20, 30}}, true},
+		{"across the slanted edge", Segment{{10, 10}, {90, 90}}, triangles[0], Segment{{10, 10}, {50, 50}}, true},
+		{"across the slanted edge, the other way round", Segment{{10, 10}, {90, 90}}, triangles[1], Segment{{10, 10}, {50, 50}}, true},
+		{"beyond the slanted edge", Segment{{60, 60}, {90, 20}}, triangles[0], Segment{}, false},
+		{"along an edge", Segment{{-10, 0}, {50, 0}}, triangles[0], Segment{{0, 0}, {50, 0}}, true},
+		{"across", Segment{{-10, 50}, {110, 50}}, hexagon, Segment{{0, 50}, {100, 50}}, true},
+		{"cutting a corner", Segment{{0, 40}, {40, -10}}, hexagon, Segment{{24, 10}, {32, 0}}, true},
+		{"by a corner", Segment{{0, 10}, {10, 0}}, hexagon, Segment{}, false},
+	}
+	for _, test := range tests {
+		got, ok, _ := ClipConvex(test.seg, test.polygon)
+		if ok != test.ok {
+			t.Errorf("%s: ok = %v, want %v", test.name, ok, test.ok)
+			continue
+		}
+		if ok && (distance(got[0], test.want[0]) > 1e-9 || distance(got[1], test.want[1]) > 1e-9) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestMidpointTrace(t *testing.T) {
+	for _, seg := range []Segment{
+		{{50, 50}, {150, 50}},
+		{{-50, 50}, {150, 70}},
+		{{-10, 9.9}, {9.9, -10}},
+		{{-10, 10.5}, {10.5, -10}},
+	} {
+		want, wantOK, steps := Midpoint{}.Clip(seg, window)
+		got, ok, trace := MidpointTrace(seg, window)
+		if got != want || ok != wantOK {
+			t.Errorf("%v: traced %v, %v, want %v, %v", seg, got, ok, want, wantOK)
+		}
+		if len(trace) != steps {
+			t.Errorf("%v: traced %d halvings, want %d", seg, len(trace), steps)
+		}
+	}
+}
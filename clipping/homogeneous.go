@@ -0,0 +1,149 @@
+package clipping
+
+// Vec4 is a point in homogeneous clip space, after the projection matrix
+// and before the perspective division.
+type Vec4 struct {
+	X, Y, Z, W float64
+}
+
+// Segment4 is a segment in clip space.
+type Segment4 [2]Vec4
+
+// Triangle4 is a triangle in clip space.
+type Triangle4 [3]Vec4
+
+// Outcode bits of a point lying outside of the canonical view volume
+// -w ≤ x, y, z ≤ w.
+const (
+	LeftPlane = 1 << iota
+	RightPlane
+	BottomPlane
+	TopPlane
+	NearPlane
+	FarPlane
+)
+
+// planes is the number of planes bounding the view volume.
+const planes = 6
+
+// boundaries returns the signed distances of v to the planes of the view
+// volume in the order of the outcode bits, scaled by w. A point is inside
+// when all of them are non-negative. Clipping against them works before the
+// perspective division, so points behind the eye are clipped too.
+func (v Vec4) boundaries() [planes]float64 {
+	return [planes]float64{v.W + v.X, v.W - v.X, v.W + v.Y, v.W - v.Y, v.W + v.Z, v.W - v.Z}
+}
+
+// Code returns the outcode of v: the planes of the view volume that v lies
+// outside of.
+func (v Vec4) Code() int {
+	code := 0
+	for i, d := range v.boundaries() {
+		if d < 0 {
+			code |= 1 << uint(i)
+		}
+	}
+	return code
+}
+
+// NDC divides v by w and returns its normalised device coordinates.
+func (v Vec4) NDC() (x, y, z float64) {
+	return v.X / v.W, v.Y / v.W, v.Z / v.W
+}
+
+func lerp4(a, b Vec4, t float64) Vec4 {
+	return Vec4{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t, a.Z + (b.Z-a.Z)*t, a.W + (b.W-a.W)*t}
+}
+
+// ClipSegment3D clips seg by the view volume with the Cohen–Sutherland
+// algorithm extended to six planes: an end outside of a plane is moved onto
+// it until the segment is trivially accepted or rejected.
+func ClipSegment3D(seg Segment4) (Segment4, bool) {
+	for {
+		first, second := seg[0].Code(), seg[1].Code()
+		if first|second == 0 {
+			return seg, true
+		}
+		if first&second != 0 {
+			return Segment4{}, false
+		}
+		i, code := 0, first
+		if first == 0 {
+			i, code = 1, second
+		}
+		plane := 0
+		for code&(1<<uint(plane)) == 0 {
+			plane++
+		}
+		p, q := seg[i].boundaries()[plane], seg[1-i].boundaries()[plane]
+		moved := lerp4(seg[i], seg[1-i], p/(p-q))
+		//точка ложится на плоскость точно, без погрешности округления
+		switch plane {
+		case 0:
+			moved.X = -moved.W
+		case 1:
+			moved.X = moved.W
+		case 2:
+			moved.Y = -moved.W
+		case 3:
+			moved.Y = moved.W
+		case 4:
+			moved.Z = -moved.W
+		case 5:
+			moved.Z = moved.W
+		}
+		seg[i] = moved
+	}
+}
+
+// LiangBarsky3D clips seg by the view volume narrowing its parameter range
+// by the six planes in clip space.
+func LiangBarsky3D(seg Segment4) (Segment4, bool) {
+	start, end := seg[0].boundaries(), seg[1].boundaries()
+	tStart, tEnd := 0.0, 1.0
+	for i := 0; i < planes; i++ {
+		if start[i] < 0 && end[i] < 0 {
+			return Segment4{}, false
+		}
+		t := start[i] / (start[i] - end[i])
+		if start[i] < 0 && t > tStart {
+			tStart = t
+		}
+		if end[i] < 0 && t < tEnd {
+			tEnd = t
+		}
+		if tStart > tEnd {
+			return Segment4{}, false
+		}
+	}
+	return Segment4{lerp4(seg[0], seg[1], tStart), lerp4(seg[0], seg[1], tEnd)}, true
+}
+
+// ClipTriangle clips t by the view volume plane after plane (the
+// Sutherland–Hodgman algorithm in clip space) and returns the resulting
+// convex polygon split into a fan of triangles, which keep the winding of
+// t. It returns nothing if t is invisible.
+func ClipTriangle(t Triangle4) []Triangle4 {
+	polygon := []Vec4{t[0], t[1], t[2]}
+	for i := 0; i < planes && len(polygon) > 0; i++ {
+		out := []Vec4{}
+		prev := polygon[len(polygon)-1]
+		prevSide := prev.boundaries()[i]
+		for _, cur := range polygon {
+			side := cur.boundaries()[i]
+			if (side >= 0) != (prevSide >= 0) {
+				out = append(out, lerp4(prev, cur, prevSide/(prevSide-side)))
+			}
+			if side >= 0 {
+				out = append(out, cur)
+			}
+			prev, prevSide = cur, side
+		}
+		polygon = out
+	}
+	triangles := []Triangle4{}
+	for i := 2; i < len(polygon); i++ {
+		triangles = append(triangles, Triangle4{polygon[0], polygon[i-1], polygon[i]})
+	}
+	return triangles
+}
@@ -0,0 +1,110 @@
+package clipping
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// epsilon is the tolerance for clip-space coordinates, which are around 1.
+const epsilon = 1e-9
+
+func near4(a, b Vec4) bool {
+	return math.Abs(a.X-b.X) <= epsilon && math.Abs(a.Y-b.Y) <= epsilon &&
+		math.Abs(a.Z-b.Z) <= epsilon && math.Abs(a.W-b.W) <= epsilon
+}
+
+// inVolume reports whether v lies in -w ≤ x, y, z ≤ w up to epsilon.
+func inVolume(v Vec4) bool {
+	for _, d := range v.boundaries() {
+		if d < -epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+func randomVec4(rnd *rand.Rand) Vec4 {
+	return Vec4{rnd.Float64()*4 - 2, rnd.Float64()*4 - 2, rnd.Float64()*4 - 2, rnd.Float64()*3 - 1}
+}
+
+func TestClipSegment3D(t *testing.T) {
+	tests := []struct {
+		name string
+		seg  Segment4
+		want Segment4
+		ok   bool
+	}{
+		{"inside", Segment4{{0.5, -0.5, 0, 1}, {-1, 1, 1, 2}}, Segment4{{0.5, -0.5, 0, 1}, {-1, 1, 1, 2}}, true},
+		{"across the right plane", Segment4{{0, 0, 0, 1}, {2, 0, 0, 1}}, Segment4{{0, 0, 0, 1}, {1, 0, 0, 1}}, true},
+		// The end behind the eye is cut at the near plane, not projected.
+		{"behind the eye", Segment4{{0, 0, 0.5, 1}, {0, 0, -3, -1}}, Segment4{{0, 0, 0.5, 1}, {0, 0, -5.0 / 11, 5.0 / 11}}, true},
+		// After the division by w both ends are at the centre of the screen.
+		{"all behind the eye", Segment4{{0, 0, 0, -1}, {0, 0, 0, -2}}, Segment4{}, false},
+		{"beyond the far plane", Segment4{{0, 0, 2, 1}, {0.5, 0, 3, 1}}, Segment4{}, false},
+	}
+	for _, test := range tests {
+		for _, clip := range []func(Segment4) (Segment4, bool){ClipSegment3D, LiangBarsky3D} {
+			got, ok := clip(test.seg)
+			if ok != test.ok {
+				t.Errorf("%s: ok = %v, want %v", test.name, ok, test.ok)
+				continue
+			}
+			if ok && (!near4(got[0], test.want[0]) || !near4(got[1], test.want[1])) {
+				t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			}
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		seg := Segment4{randomVec4(rnd), randomVec4(rnd)}
+		got, ok := ClipSegment3D(seg)
+		want, wantOK := LiangBarsky3D(seg)
+		if ok != wantOK {
+			t.Fatalf("%v: ok = %v, Liang-Barsky says %v", seg, ok, wantOK)
+		}
+		if ok && (!near4(got[0], want[0]) || !near4(got[1], want[1])) {
+			t.Fatalf("%v: got %v, Liang-Barsky gives %v", seg, got, want)
+		}
+	}
+}
+
+func TestClipTriangle(t *testing.T) {
+	inside := Triangle4{{-0.5, -0.5, 0, 1}, {0.5, -0.5, 0, 1}, {0, 0.5, 0, 1}}
+	if got := ClipTriangle(inside); len(got) != 1 || got[0] != inside {
+		t.Errorf("inside: got %v, want %v", got, []Triangle4{inside})
+	}
+	behind := Triangle4{{-0.5, -0.5, 0, -1}, {0.5, -0.5, 0, -1}, {0, 0.5, 0, -1}}
+	if got := ClipTriangle(behind); len(got) != 0 {
+		t.Errorf("behind the eye: got %v, want nothing", got)
+	}
+	across := Triangle4{{0, 0, 0, 1}, {2, 0, 0, 1}, {0, 0.5, 0, 1}}
+	want := []Triangle4{
+		{{0, 0, 0, 1}, {1, 0, 0, 1}, {1, 0.25, 0, 1}},
+		{{0, 0, 0, 1}, {1, 0.25, 0, 1}, {0, 0.5, 0, 1}},
+	}
+	got := ClipTriangle(across)
+	if len(got) != len(want) {
+		t.Fatalf("across the right plane: got %v, want %v", got, want)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if !near4(got[i][j], want[i][j]) {
+				t.Errorf("across the right plane: got %v, want %v", got, want)
+			}
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		tri := Triangle4{randomVec4(rnd), randomVec4(rnd), randomVec4(rnd)}
+		for _, fan := range ClipTriangle(tri) {
+			for _, v := range fan {
+				if !inVolume(v) {
+					t.Fatalf("%v: fan vertex %v is outside of the view volume", tri, v)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package clipping
+
+import "math"
+
+// FixedBits is the number of fractional bits of the fixed-point arithmetic
+// of IntegerMidpoint.
+const FixedBits = 16
+
+// fixed is a point in fixed-point arithmetic with FixedBits fractional bits.
+type fixed struct {
+	x, y int64
+}
+
+const fixedOne = int64(1) << FixedBits
+
+func toFixed(p Point) fixed {
+	return fixed{int64(math.Round(p.X * float64(fixedOne))), int64(math.Round(p.Y * float64(fixedOne)))}
+}
+
+func (p fixed) point() Point {
+	return Point{float64(p.x) / float64(fixedOne), float64(p.y) / float64(fixedOne)}
+}
+
+// pixel rounds p to the nearest pixel and moves it into r: to the nearest
+// pixel inside r or, if there is none, onto its border.
+func (p fixed) pixel(r Rect) Point {
+	x, y := float64((p.x+fixedOne/2)>>FixedBits), float64((p.y+fixedOne/2)>>FixedBits)
+	return Point{clampPixel(x, r.Left, r.Right), clampPixel(y, r.Top, r.Bottom)}
+}
+
+func clampPixel(v, low, high float64) float64 {
+	if math.Ceil(low) <= math.Floor(high) {
+		low, high = math.Ceil(low), math.Floor(high)
+	}
+	return math.Max(low, math.Min(high, v))
+}
+
+func (p fixed) midpoint(q fixed) fixed {
+	return fixed{(p.x + q.x) >> 1, (p.y + q.y) >> 1}
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// adjacent reports whether p and q are neighbouring fixed-point values that
+// cannot be halved any further.
+func adjacent(p, q fixed) bool {
+	return abs(p.x-q.x) <= 1 && abs(p.y-q.y) <= 1
+}
+
+// IntegerMidpoint is midpoint subdivision in integer arithmetic: the ends
+// are converted to fixed point and the segment is halved by shifts until
+// the ends are neighbouring fixed-point values, so an invisible end is
+// replaced by a point on the border of the window to within 2^-FixedBits.
+// Only such an end is rounded to the nearest pixel, which puts it on the
+// border pixel inside the window; visible ends are returned as they are.
+// Its steps are the halvings.
+type IntegerMidpoint struct{}
+
+func (IntegerMidpoint) String() string {
+	return "integer midpoint"
+}
+
+func (IntegerMidpoint) Clip(seg Segment, r Rect) (Segment, bool, int) {
+	ends := [2]fixed{toFixed(seg[0]), toFixed(seg[1])}
+	moved := [2]bool{}
+	result := func() Segment {
+		clipped := seg
+		for i := range ends {
+			if moved[i] {
+				clipped[i] = ends[i].pixel(r)
+			}
+		}
+		return clipped
+	}
+	steps := 0
+	for pass := 0; ; pass++ {
+		first, second := r.Code(ends[0].point()), r.Code(ends[1].point())
+		if first|second == 0 {
+			//после нечётного числа проходов концы переставлены
+			if pass%2 == 1 {
+				ends[0], ends[1] = ends[1], ends[0]
+				moved[0], moved[1] = moved[1], moved[0]
+			}
+			return result(), true, steps
+		}
+		if first&second != 0 {
+			return Segment{}, false, steps
+		}
+		if pass == 2 {
+			//как и Midpoint: концы у разных сторон, отрезок может пройти мимо угла
+			ok, n := visibleFixed(ends[0], ends[1], r)
+			if !ok {
+				return Segment{}, false, steps + n
+			}
+			return result(), true, steps + n
+		}
+		if second != 0 {
+			var n int
+			ends[1], n = approachFixed(ends[0], ends[1], second, r)
+			moved[1] = true
+			steps += n
+		}
+		ends[0], ends[1] = ends[1], ends[0]
+		moved[0], moved[1] = moved[1], moved[0]
+	}
+}
+
+// approachFixed halves the segment from a to the invisible end b with
+// outcode code and returns the last point that is not outside on the sides
+// of b, along with the number of halvings.
+func approachFixed(a, b fixed, code int, r Rect) (fixed, int) {
+	steps := 0
+	for !adjacent(a, b) {
+		midpoint := a.midpoint(b)
+		if r.Code(midpoint.point())&code != 0 {
+			b = midpoint
+		} else {
+			a = midpoint
+		}
+		steps++
+	}
+	return a, steps
+}
+
+// visibleFixed is visible in fixed point: a piece whose ends are
+// neighbouring fixed-point values is taken as touching r.
+func visibleFixed(a, b fixed, r Rect) (bool, int) {
+	steps := 0
+	pieces := [][2]fixed{{a, b}}
+	for len(pieces) > 0 {
+		piece := pieces[len(pieces)-1]
+		pieces = pieces[:len(pieces)-1]
+		first, second := r.Code(piece[0].point()), r.Code(piece[1].point())
+		if first&second != 0 {
+			continue
+		}
+		if first == 0 || second == 0 || adjacent(piece[0], piece[1]) {
+			return true, steps
+		}
+		midpoint := piece[0].midpoint(piece[1])
+		steps++
+		pieces = append(pieces, [2]fixed{piece[0], midpoint}, [2]fixed{midpoint, piece[1]})
+	}
+	return false, steps
+}
@@ -0,0 +1,176 @@
+package clipping
+
+import (
+	"math"
+	"sort"
+)
+
+func cross(a, b Point) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// area returns the doubled signed area of polygon.
+func area(polygon []Point) float64 {
+	sum := 0.0
+	for i, p := range polygon {
+		sum += cross(p, polygon[(i+1)%len(polygon)])
+	}
+	return sum
+}
+
+// IsConvex reports whether polygon turns the same way at every vertex.
+func IsConvex(polygon []Point) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+	sign := 0.0
+	for i, p := range polygon {
+		next, after := polygon[(i+1)%len(polygon)], polygon[(i+2)%len(polygon)]
+		turn := cross(sub(next, p), sub(after, next))
+		if turn*sign < 0 {
+			return false
+		}
+		if turn != 0 {
+			sign = turn
+		}
+	}
+	return true
+}
+
+// hullIndices returns the indices of the vertices of polygon lying on its
+// convex hull in increasing order, found by the monotone chain algorithm.
+// Vertices in the middle of a hull edge are left out.
+func hullIndices(polygon []Point) []int {
+	order := make([]int, len(polygon))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := polygon[order[i]], polygon[order[j]]
+		return a.X < b.X || (a.X == b.X && a.Y < b.Y)
+	})
+	hull := []int{}
+	chain := func(order []int) {
+		start := len(hull)
+		for _, i := range order {
+			for len(hull) >= start+2 {
+				a, b := polygon[hull[len(hull)-2]], polygon[hull[len(hull)-1]]
+				if cross(sub(b, a), sub(polygon[i], b)) > 0 {
+					break
+				}
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, i)
+		}
+		hull = hull[:len(hull)-1]
+	}
+	chain(order)
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	chain(order)
+	sort.Ints(hull)
+	return hull
+}
+
+// completePolygon splits the simple polygon into its convex hull and the
+// pockets: the polygons between an edge of the hull and the part of the
+// boundary it spans. The pockets do not overlap and the hull minus the
+// pockets is the original polygon. A pocket need not be convex.
+func completePolygon(polygon []Point) (hull []Point, pockets [][]Point) {
+	polygon = append([]Point{}, polygon...)
+	if area(polygon) < 0 {
+		for i, j := 0, len(polygon)-1; i < j; i, j = i+1, j-1 {
+			polygon[i], polygon[j] = polygon[j], polygon[i]
+		}
+	}
+	indices := hullIndices(polygon)
+	if len(indices) < 3 {
+		return polygon, nil
+	}
+	for k, i := range indices {
+		hull = append(hull, polygon[i])
+		next := indices[(k+1)%len(indices)]
+		pocket := []Point{}
+		for j := i; j != next; j = (j + 1) % len(polygon) {
+			pocket = append(pocket, polygon[j])
+		}
+		pocket = append(pocket, polygon[next])
+		if len(pocket) > 2 && area(pocket) != 0 {
+			pockets = append(pockets, pocket)
+		}
+	}
+	return hull, pockets
+}
+
+// insideRanges returns the ranges of the parameter of seg inside the simple
+// polygon, in increasing order: the range inside its convex hull minus the
+// ranges inside the pockets, each of them clipped the same way.
+func insideRanges(seg Segment, polygon []Point) [][2]float64 {
+	hull, pockets := completePolygon(polygon)
+	tStart, tEnd, ok, _ := convexRange(seg, hull)
+	if !ok {
+		return nil
+	}
+	ranges := [][2]float64{{tStart, tEnd}}
+	for _, pocket := range pockets {
+		for _, cut := range insideRanges(seg, pocket) {
+			rest := [][2]float64{}
+			for _, r := range ranges {
+				if r[0] < cut[0] {
+					rest = append(rest, [2]float64{r[0], math.Min(r[1], cut[0])})
+				}
+				if r[1] > cut[1] {
+					rest = append(rest, [2]float64{math.Max(r[0], cut[1]), r[1]})
+				}
+			}
+			ranges = rest
+		}
+	}
+	return ranges
+}
+
+// ClipConvexOutside returns the parts of seg outside of the convex polygon:
+// none, one or two segments.
+func ClipConvexOutside(seg Segment, polygon []Point) []Segment {
+	inside, ok, _ := ClipConvex(seg, polygon)
+	if !ok {
+		return []Segment{seg}
+	}
+	outside := []Segment{}
+	if inside[0] != seg[0] {
+		outside = append(outside, Segment{seg[0], inside[0]})
+	}
+	if inside[1] != seg[1] {
+		outside = append(outside, Segment{inside[1], seg[1]})
+	}
+	return outside
+}
+
+// ClipPolygon clips seg by an arbitrary simple polygon in two stages: by
+// its convex hull with the Cyrus–Beck algorithm and then by the pockets
+// between the hull and the polygon, which are clipped the same way. With
+// inner set it returns the parts of seg inside the polygon, otherwise the
+// parts outside of it.
+func ClipPolygon(seg Segment, polygon []Point, inner bool) []Segment {
+	ranges := [][2]float64{}
+	if len(polygon) >= 3 {
+		ranges = insideRanges(seg, polygon)
+	}
+	if !inner {
+		outside := [][2]float64{}
+		last := 0.0
+		for _, r := range ranges {
+			outside = append(outside, [2]float64{last, r[0]})
+			last = r[1]
+		}
+		ranges = append(outside, [2]float64{last, 1})
+	}
+	parts := []Segment{}
+	for _, r := range ranges {
+		if r[0] < r[1] {
+			parts = append(parts, Segment{seg.at(r[0]), seg.at(r[1])})
+		}
+	}
+	return parts
+}
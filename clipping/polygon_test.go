@@ -0,0 +1,85 @@
+package clipping
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// spiral returns a simple polygon winding turns times around (50, 50).
+func spiral(turns float64) []Point {
+	inner, outer := []Point{}, []Point{}
+	for a := 0.0; a <= turns*2*math.Pi; a += 0.2 {
+		r := 5 + 6*a
+		inner = append(inner, Point{50 + r*math.Cos(a), 50 + r*math.Sin(a)})
+		outer = append(outer, Point{50 + (r+4)*math.Cos(a), 50 + (r+4)*math.Sin(a)})
+	}
+	for i := len(outer) - 1; i >= 0; i-- {
+		inner = append(inner, outer[i])
+	}
+	return inner
+}
+
+var windows = map[string][]Point{
+	"concave":  {{30, 70}, {80, 70}, {10, 80}, {90, 10}, {60, 60}, {70, 40}},
+	"star":     {{50, 0}, {61, 35}, {98, 35}, {68, 57}, {79, 91}, {50, 70}, {21, 91}, {32, 57}, {2, 35}, {39, 35}},
+	"comb":     {{0, 0}, {100, 0}, {100, 100}, {80, 100}, {80, 20}, {60, 20}, {60, 100}, {40, 100}, {40, 20}, {20, 20}, {20, 100}, {0, 100}},
+	"spiral":   spiral(2.5),
+	"triangle": {{10, 10}, {90, 30}, {40, 90}},
+}
+
+// checkParts checks that the parts of seg clipped by polygon with inner set
+// lie inside of it and the ones without outside, and that together they
+// cover seg, sampling both away from the ends of the parts.
+func checkParts(t *testing.T, name string, seg Segment, polygon []Point) {
+	length := distance(seg[0], seg[1])
+	const margin = 1e-6
+	for _, inner := range []bool{true, false} {
+		for _, part := range ClipPolygon(seg, polygon, inner) {
+			if distance(part[0], part[1]) < 2*margin {
+				continue
+			}
+			for k := 1; k < 10; k++ {
+				p := Segment{part[0], part[1]}.at(float64(k) / 10)
+				if Contains([][]Point{polygon}, p) != inner {
+					t.Fatalf("%s: %v clipped with inner %v gives %v, but %v is not on that side", name, seg, inner, part, p)
+				}
+			}
+		}
+	}
+	parts := append(ClipPolygon(seg, polygon, true), ClipPolygon(seg, polygon, false)...)
+	for k := 0; k <= 100; k++ {
+		p := seg.at(float64(k) / 100)
+		covered := false
+		for _, part := range parts {
+			if math.Abs(distance(part[0], p)+distance(p, part[1])-distance(part[0], part[1])) <= margin*length {
+				covered = true
+			}
+		}
+		if !covered {
+			t.Fatalf("%s: %v is not covered by %v", name, p, parts)
+		}
+	}
+}
+
+func TestClipPolygon(t *testing.T) {
+	seg := Segment{{52.38, 32.14}, {80.29, 25.65}}
+	parts := ClipPolygon(seg, windows["concave"], true)
+	//отрезок входит в многоугольник через ребро (10, 80)-(90, 10)
+	edge := Segment{{10, 80}, {90, 10}}
+	if len(parts) != 1 || parts[0][1] != seg[1] || math.Abs(cross(sub(edge[1], edge[0]), sub(parts[0][0], edge[0]))) > 1e-9 {
+		t.Errorf("concave window: %v clipped to %v, want the part from the edge %v to the end", seg, parts, edge)
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for name, polygon := range windows {
+		for i := 0; i < 500; i++ {
+			seg := Segment{{random.Float64()*140 - 20, random.Float64()*140 - 20}, {random.Float64()*140 - 20, random.Float64()*140 - 20}}
+			checkParts(t, name, seg, polygon)
+		}
+		//диагонали: отрезки между вершинами через одну
+		for i := 0; len(polygon) > 3 && i < len(polygon); i++ {
+			checkParts(t, name, Segment{polygon[i], polygon[(i+2)%len(polygon)]}, polygon)
+		}
+	}
+}
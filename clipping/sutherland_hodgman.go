@@ -0,0 +1,42 @@
+package clipping
+
+// SutherlandHodgman clips the subject polygon by the convex window: every
+// contour is cut by the edges of window one after another. A contour that
+// goes out of the window comes back along its border, so the result should
+// be filled with the even-odd rule. Contours left with fewer than three
+// vertices are dropped, and so is everything if window has zero area.
+func SutherlandHodgman(subject [][]Point, window []Point) [][]Point {
+	result := [][]Point{}
+	n := normals(window)
+	if n == nil {
+		return result
+	}
+	for _, contour := range subject {
+		for i := 0; i < len(n) && len(contour) > 0; i++ {
+			contour = cutByEdge(contour, window[i], n[i])
+		}
+		if len(contour) >= 3 {
+			result = append(result, contour)
+		}
+	}
+	return result
+}
+
+// cutByEdge keeps the part of contour on the inner side of the line through
+// a with the inner normal n.
+func cutByEdge(contour []Point, a, n Point) []Point {
+	out := []Point{}
+	prev := contour[len(contour)-1]
+	prevSide := dot(n, sub(prev, a))
+	for _, cur := range contour {
+		side := dot(n, sub(cur, a))
+		if (side >= 0) != (prevSide >= 0) {
+			out = append(out, Segment{prev, cur}.at(prevSide/(prevSide-side)))
+		}
+		if side >= 0 {
+			out = append(out, cur)
+		}
+		prev, prevSide = cur, side
+	}
+	return out
+}
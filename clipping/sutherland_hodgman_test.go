@@ -0,0 +1,45 @@
+package clipping
+
+import "testing"
+
+func TestSutherlandHodgman(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject [][]Point
+		window  []Point
+		want    []Point
+	}{
+		{"inside", [][]Point{square(2, 2, 8, 8)}, square(0, 0, 10, 10), square(2, 2, 8, 8)},
+		{"crossing", [][]Point{square(0, 0, 10, 10)}, square(5, 5, 15, 15), square(5, 5, 10, 10)},
+		{"shared top and bottom", [][]Point{square(0, 0, 10, 10)}, square(5, 0, 15, 10), square(5, 0, 10, 10)},
+		{"identical", [][]Point{square(0, 0, 10, 10)}, square(0, 0, 10, 10), square(0, 0, 10, 10)},
+		{"window inside", [][]Point{square(0, 0, 10, 10)}, []Point{{5, 2}, {8, 8}, {2, 8}}, []Point{{5, 2}, {8, 8}, {2, 8}}},
+		{"window going the other way", [][]Point{square(0, 0, 10, 10)}, []Point{{5, 5}, {5, 15}, {15, 15}, {15, 5}}, square(5, 5, 10, 10)},
+	}
+	for _, test := range tests {
+		got := SutherlandHodgman(test.subject, test.window)
+		checkVertices(t, test.name, got, test.want)
+		checkIntersection(t, test.name, got, test.subject, [][]Point{test.window})
+	}
+
+	if got := SutherlandHodgman([][]Point{square(0, 0, 10, 10)}, square(20, 0, 30, 10)); len(got) != 0 {
+		t.Errorf("disjoint: got %v, want nothing", got)
+	}
+
+	convex := map[string][]Point{
+		"triangle": windows["triangle"],
+		"square":   square(20, 20, 80, 80),
+		"hexagon":  {{30, 5}, {70, 5}, {95, 50}, {70, 95}, {30, 95}, {5, 50}},
+	}
+	subjects := map[string][][]Point{
+		"concave":   {windows["concave"]},
+		"comb":      {windows["comb"]},
+		"with hole": {square(10, 10, 90, 90), square(30, 30, 70, 70)},
+	}
+	for subjectName, subject := range subjects {
+		for windowName, window := range convex {
+			name := subjectName + " by " + windowName
+			checkIntersection(t, name, SutherlandHodgman(subject, window), subject, [][]Point{window})
+		}
+	}
+}
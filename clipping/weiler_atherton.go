@@ -0,0 +1,247 @@
+package clipping
+
+import (
+	"math"
+	"sort"
+)
+
+// node is a vertex of a contour ring. Intersections are present in the
+// rings of both polygons and linked to each other through neighbour. p is
+// where the vertex is after the perturbation, source where it is reported.
+type node struct {
+	p            Point
+	source       Point
+	next, prev   *node
+	neighbour    *node
+	intersection bool
+	entry        bool
+	visited      bool
+	t            float64
+}
+
+// makeRing links the vertices of contour moved by offset into a ring.
+func makeRing(contour []Point, offset Point) *node {
+	var first, last *node
+	for _, p := range contour {
+		n := &node{p: Point{p.X + offset.X, p.Y + offset.Y}, source: p}
+		if first == nil {
+			first = n
+		} else {
+			last.next, n.prev = n, last
+		}
+		last = n
+	}
+	last.next, first.prev = first, last
+	return first
+}
+
+// ringEdges returns the original vertices of a ring, before intersections
+// are inserted: the i-th edge goes from the i-th one to the next.
+func ringEdges(first *node) []*node {
+	edges := []*node{first}
+	for n := first.next; n != first; n = n.next {
+		edges = append(edges, n)
+	}
+	return edges
+}
+
+// insert puts the intersections found on the edge starting at start into
+// the ring, sorted by their parameter on the edge.
+func insert(start *node, found []*node) {
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].t < found[j].t
+	})
+	end := start.next
+	for _, n := range found {
+		n.prev, n.next = end.prev, end
+		end.prev.next, end.prev = n, n
+	}
+}
+
+// intersect returns the parameters of the intersection of segments a and
+// b on both of them, if they cross at inner points.
+func intersect(a, b Segment) (ta, tb float64, ok bool) {
+	da, db := sub(a[1], a[0]), sub(b[1], b[0])
+	den := cross(da, db)
+	if den == 0 {
+		return 0, 0, false
+	}
+	diff := sub(b[0], a[0])
+	ta, tb = cross(diff, db)/den, cross(diff, da)/den
+	return ta, tb, ta > 0 && ta < 1 && tb > 0 && tb < 1
+}
+
+// Contains reports whether p is inside the polygon made of contours by the
+// even-odd rule.
+func Contains(contours [][]Point, p Point) bool {
+	in := false
+	for _, contour := range contours {
+		for i, a := range contour {
+			b := contour[(i+1)%len(contour)]
+			if (a.Y > p.Y) != (b.Y > p.Y) && p.X < a.X+(p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+				in = !in
+			}
+		}
+	}
+	return in
+}
+
+// markEntries sets for every intersection of ring whether the boundary goes
+// inside other after it.
+func markEntries(ring *node, other [][]Point) {
+	n := ring
+	for {
+		if n.intersection {
+			n.entry = Contains(other, Segment{n.p, n.next.p}.at(0.5))
+		}
+		n = n.next
+		if n == ring {
+			return
+		}
+	}
+}
+
+func validContours(polygon [][]Point) [][]Point {
+	valid := [][]Point{}
+	for _, contour := range polygon {
+		if len(contour) >= 3 {
+			valid = append(valid, contour)
+		}
+	}
+	return valid
+}
+
+// perturbation is the offset the clip polygon is moved by in
+// WeilerAtherton. Its direction is not that of any edge with small integer
+// coordinates, so after the move no vertex lies on an edge of the other
+// polygon and no edges overlap.
+var perturbation = Point{1e-7, math.Sqrt2 * 1e-7}
+
+// WeilerAtherton returns the intersection of the subject and clip polygons.
+// Both may be concave and have holes: every polygon is a list of contours
+// read with the even-odd rule, and so is the result. The boundaries are
+// walked from one intersection to the next, switching to the other polygon
+// at every intersection. Contours without intersections are kept if they
+// lie inside the other polygon. Intersections at vertices and overlapping
+// edges, common with integer input, are avoided by moving clip by
+// perturbation, so the result may be off by as much.
+func WeilerAtherton(subject, clip [][]Point) [][]Point {
+	subject, clip = validContours(subject), validContours(clip)
+	subjectRings, clipRings := make([]*node, len(subject)), make([]*node, len(clip))
+	for i, contour := range subject {
+		subjectRings[i] = makeRing(contour, Point{})
+	}
+	moved := make([][]Point, len(clip))
+	for i, contour := range clip {
+		clipRings[i] = makeRing(contour, perturbation)
+		for _, p := range contour {
+			moved[i] = append(moved[i], Point{p.X + perturbation.X, p.Y + perturbation.Y})
+		}
+	}
+
+	crossed := map[*node]bool{}
+	clipEdges := [][]*node{}
+	for _, ring := range clipRings {
+		clipEdges = append(clipEdges, ringEdges(ring))
+	}
+	found := map[*node][]*node{}
+	for _, subjectRing := range subjectRings {
+		for _, a := range ringEdges(subjectRing) {
+			for k, edges := range clipEdges {
+				for _, b := range edges {
+					ta, tb, ok := intersect(Segment{a.p, a.next.p}, Segment{b.p, b.next.p})
+					if !ok {
+						continue
+					}
+					p := Segment{a.p, a.next.p}.at(ta)
+					onSubject := &node{p: p, source: p, intersection: true, t: ta}
+					onClip := &node{p: p, source: p, intersection: true, t: tb}
+					onSubject.neighbour, onClip.neighbour = onClip, onSubject
+					found[a] = append(found[a], onSubject)
+					found[b] = append(found[b], onClip)
+					crossed[subjectRing], crossed[clipRings[k]] = true, true
+				}
+			}
+		}
+	}
+	for start, nodes := range found {
+		insert(start, nodes)
+	}
+
+	result := [][]Point{}
+	for i, ring := range subjectRings {
+		if !crossed[ring] && Contains(moved, subject[i][0]) {
+			result = append(result, subject[i])
+		}
+	}
+	for i, ring := range clipRings {
+		if !crossed[ring] && Contains(subject, moved[i][0]) {
+			result = append(result, clip[i])
+		}
+	}
+	for _, ring := range subjectRings {
+		markEntries(ring, moved)
+	}
+	for _, ring := range clipRings {
+		markEntries(ring, subject)
+	}
+
+	for _, ring := range subjectRings {
+		start := ring
+		for {
+			if start.intersection && !start.visited {
+				//контур вдоль общей грани схлопывается
+				if contour := walk(start); len(contour) >= 3 {
+					result = append(result, contour)
+				}
+			}
+			start = start.next
+			if start == ring {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// walk traces one contour of the intersection starting at the intersection
+// start: forward along a boundary that goes inside the other polygon,
+// backward otherwise.
+func walk(start *node) []Point {
+	contour := []Point{}
+	n := start
+	for !n.visited {
+		n.visited, n.neighbour.visited = true, true
+		contour = appendVertex(contour, n.source)
+		forward := n.entry
+		for {
+			if forward {
+				n = n.next
+			} else {
+				n = n.prev
+			}
+			if n.intersection {
+				break
+			}
+			contour = appendVertex(contour, n.source)
+		}
+		n = n.neighbour
+	}
+	if len(contour) > 1 && merged(contour[0], contour[len(contour)-1]) {
+		contour = contour[:len(contour)-1]
+	}
+	return contour
+}
+
+// merged reports whether a and b are apart only because of the
+// perturbation, as two intersections next to a vertex lying on an edge.
+func merged(a, b Point) bool {
+	return math.Abs(a.X-b.X) <= 2*perturbation.X+2*perturbation.Y && math.Abs(a.Y-b.Y) <= 2*perturbation.X+2*perturbation.Y
+}
+
+func appendVertex(contour []Point, p Point) []Point {
+	if len(contour) > 0 && merged(contour[len(contour)-1], p) {
+		return contour
+	}
+	return append(contour, p)
+}
@@ -0,0 +1,82 @@
+package clipping
+
+import "testing"
+
+func square(left, top, right, bottom float64) []Point {
+	return Rect{left, top, right, bottom}.Polygon()
+}
+
+// checkIntersection checks, on a grid of points away from integer
+// coordinates, that got covers exactly the points inside both subject and
+// clip by the even-odd rule.
+func checkIntersection(t *testing.T, name string, got, subject, clip [][]Point) {
+	for x := -5.0; x < 105; x++ {
+		for y := -5.0; y < 105; y++ {
+			p := Point{x + 0.31, y + 0.73}
+			want := Contains(subject, p) && Contains(clip, p)
+			if Contains(got, p) != want {
+				t.Fatalf("%s: %v is inside %v: %v, want %v", name, p, got, !want, want)
+			}
+		}
+	}
+}
+
+// checkVertices checks that the only contour of got has the vertices of
+// want in some order, up to the perturbation.
+func checkVertices(t *testing.T, name string, got [][]Point, want []Point) {
+	if len(got) != 1 || len(got[0]) != len(want) {
+		t.Errorf("%s: got %v, want %v", name, got, want)
+		return
+	}
+	for _, w := range want {
+		found := false
+		for _, p := range got[0] {
+			if distance(p, w) < 1e-6 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestWeilerAtherton(t *testing.T) {
+	tests := []struct {
+		name          string
+		subject, clip [][]Point
+		want          []Point
+	}{
+		{"crossing", [][]Point{square(0, 0, 10, 10)}, [][]Point{square(5, 5, 15, 15)}, square(5, 5, 10, 10)},
+		{"shared top and bottom", [][]Point{square(0, 0, 10, 10)}, [][]Point{square(5, 0, 15, 10)}, square(5, 0, 10, 10)},
+		{"identical", [][]Point{square(0, 0, 10, 10)}, [][]Point{square(0, 0, 10, 10)}, square(0, 0, 10, 10)},
+		{"inside touching", [][]Point{square(0, 0, 10, 10)}, [][]Point{square(0, 2, 4, 6)}, square(0, 2, 4, 6)},
+		{"vertex on an edge", [][]Point{square(0, 0, 10, 10)}, [][]Point{{{5, 0}, {15, 5}, {5, 10}}}, []Point{{5, 0}, {10, 2.5}, {10, 7.5}, {5, 10}}},
+		{"nested", [][]Point{square(0, 0, 10, 10)}, [][]Point{square(2, 2, 8, 8)}, square(2, 2, 8, 8)},
+	}
+	for _, test := range tests {
+		got := WeilerAtherton(test.subject, test.clip)
+		checkVertices(t, test.name, got, test.want)
+		checkIntersection(t, test.name, got, test.subject, test.clip)
+	}
+
+	if got := WeilerAtherton([][]Point{square(0, 0, 10, 10)}, [][]Point{square(20, 0, 30, 10)}); len(got) != 0 {
+		t.Errorf("disjoint: got %v, want nothing", got)
+	}
+	if got := WeilerAtherton([][]Point{square(0, 0, 10, 10)}, [][]Point{square(10, 0, 20, 10)}); len(got) != 0 {
+		t.Errorf("sharing an edge: got %v, want nothing", got)
+	}
+
+	subjects := map[string][][]Point{
+		"square":    {square(20, 20, 80, 80)},
+		"with hole": {square(10, 10, 90, 90), square(30, 30, 70, 70)},
+		"diamond":   {{{50, 0}, {100, 50}, {50, 100}, {0, 50}}},
+	}
+	for subjectName, subject := range subjects {
+		for clipName, clip := range windows {
+			name := subjectName + " by " + clipName
+			checkIntersection(t, name, WeilerAtherton(subject, [][]Point{clip}), subject, [][]Point{clip})
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+
+	clip "github.com/MKondakova/Computer_graphics/clipping"
+)
+
+type config struct {
+	segments int
+	seed     int64
+	size     float64
+	padding  float64
+	subpixel bool
+}
+
+func parseFlags() config {
+	var cfg config
+	flag.IntVar(&cfg.segments, "n", 10000, "number of random segments")
+	flag.Int64Var(&cfg.seed, "seed", 1, "random seed")
+	flag.Float64Var(&cfg.size, "size", 1000, "side of the square the segments are drawn in")
+	flag.Float64Var(&cfg.padding, "padding", 0.2, "margin of the clip window as a part of size, as in LAB_5")
+	flag.BoolVar(&cfg.subpixel, "subpixel", false, "place the ends between pixels instead of on them")
+	flag.Parse()
+	return cfg
+}
+
+func distance(a, b clip.Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// report compares every clipper with the exact parametric intersection of
+// Liang-Barsky on the same random segments: how often they disagree on
+// visibility, how far their ends are from the exact ones and how many steps
+// they take.
+func report(cfg config) {
+	random := rand.New(rand.NewSource(cfg.seed))
+	window := clip.Rect{
+		Left:   cfg.size * cfg.padding,
+		Top:    cfg.size * cfg.padding,
+		Right:  cfg.size * (1 - cfg.padding),
+		Bottom: cfg.size * (1 - cfg.padding),
+	}
+	segments := make([]clip.Segment, cfg.segments)
+	for i := range segments {
+		for j := range segments[i] {
+			segments[i][j] = clip.Point{X: random.Float64() * cfg.size, Y: random.Float64() * cfg.size}
+			if !cfg.subpixel {
+				segments[i][j] = clip.Point{X: math.Round(segments[i][j].X), Y: math.Round(segments[i][j].Y)}
+			}
+		}
+	}
+
+	fmt.Printf("%-18s %10s %10s %10s %10s\n", "clipper", "mismatches", "mean error", "max error", "mean steps")
+	for _, clipper := range clip.Clippers {
+		mismatches, compared, steps := 0, 0, 0
+		sum, max := 0.0, 0.0
+		for _, segment := range segments {
+			exact, visible, _ := clip.LiangBarsky{}.Clip(segment, window)
+			clipped, ok, n := clipper.Clip(segment, window)
+			steps += n
+			if ok != visible {
+				mismatches++
+				continue
+			}
+			if !ok {
+				continue
+			}
+			for i := range clipped {
+				e := distance(clipped[i], exact[i])
+				sum += e
+				max = math.Max(max, e)
+			}
+			compared += 2
+		}
+		mean := 0.0
+		if compared > 0 {
+			mean = sum / float64(compared)
+		}
+		fmt.Printf("%-18v %10d %10.4f %10.4f %10.2f\n", clipper, mismatches, mean, max, float64(steps)/float64(len(segments)))
+	}
+}
+
+func main() {
+	report(parseFlags())
+}
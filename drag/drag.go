@@ -0,0 +1,72 @@
+package drag
+
+import (
+	"math"
+
+	"github.com/MKondakova/Computer_graphics/history"
+)
+
+// Radius is how far from a vertex, in pixels, the cursor still picks it.
+const Radius = 8
+
+// Vertex points to the coordinates of an entered vertex, whatever point
+// type holds them.
+type Vertex struct {
+	X, Y *float64
+}
+
+// Nearest returns the vertex closest to (x, y) if it is within Radius, or
+// nil.
+func Nearest(x, y float64, vertices []Vertex) *Vertex {
+	var nearest *Vertex
+	best := float64(Radius)
+	for i, v := range vertices {
+		if d := math.Hypot(*v.X-x, *v.Y-y); d <= best {
+			nearest, best = &vertices[i], d
+		}
+	}
+	return nearest
+}
+
+// Drag moves a vertex after the cursor. The whole drag, from Grab to
+// Release, is one step of Edits.
+type Drag struct {
+	Edits *history.History
+	// Moved is called after every move of the vertex.
+	Moved  func()
+	vertex *Vertex
+	start  history.State
+}
+
+// Grab starts dragging the vertex nearest to (x, y). It returns false if
+// there is none.
+func (d *Drag) Grab(x, y float64, vertices []Vertex) bool {
+	d.vertex = Nearest(x, y, vertices)
+	if d.vertex != nil {
+		d.start = d.Edits.Save()
+	}
+	return d.vertex != nil
+}
+
+// Move puts the dragged vertex at (x, y).
+func (d *Drag) Move(x, y float64) {
+	if d.vertex == nil {
+		return
+	}
+	*d.vertex.X, *d.vertex.Y = x, y
+	d.Moved()
+}
+
+// Release ends the drag and records it.
+func (d *Drag) Release() {
+	if d.vertex != nil {
+		d.vertex = nil
+		d.Edits.Commit(d.start)
+	}
+}
+
+// Cancel forgets the dragged vertex without recording anything, for when
+// the vertices it points to are replaced.
+func (d *Drag) Cancel() {
+	d.vertex = nil
+}
@@ -0,0 +1,49 @@
+package drag
+
+import (
+	"testing"
+
+	"github.com/MKondakova/Computer_graphics/history"
+)
+
+type point struct{ X, Y float64 }
+
+type snapshot struct {
+	pts   *[]point
+	saved []point
+}
+
+func (s snapshot) Restore() {
+	*s.pts = append([]point{}, s.saved...)
+}
+
+func TestDrag(t *testing.T) {
+	pts := []point{{0, 0}, {10, 0}}
+	vertices := func() []Vertex {
+		vs := []Vertex{}
+		for i := range pts {
+			vs = append(vs, Vertex{&pts[i].X, &pts[i].Y})
+		}
+		return vs
+	}
+	edits := history.History{Save: func() history.State { return snapshot{&pts, append([]point{}, pts...)} }}
+	moves := 0
+	d := Drag{Edits: &edits, Moved: func() { moves++ }}
+
+	if d.Grab(5, 5+Radius, vertices()) {
+		t.Fatal("grabbed a vertex farther than Radius")
+	}
+	if !d.Grab(7, 1, vertices()) {
+		t.Fatal("did not grab the nearest vertex")
+	}
+	d.Move(20, 5)
+	d.Move(30, 5)
+	d.Release()
+	d.Move(40, 5)
+	if pts[1] != (point{30, 5}) || moves != 2 {
+		t.Fatalf("after the drag pts = %v, %d moves", pts, moves)
+	}
+	if !edits.Undo() || pts[1] != (point{10, 0}) {
+		t.Fatalf("after undo pts = %v, want the drag undone as one step", pts)
+	}
+}
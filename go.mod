@@ -0,0 +1,9 @@
+module github.com/MKondakova/Computer_graphics
+
+go 1.19
+
+require (
+	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71
+	github.com/go-gl/glfw v0.0.0-20260823155953-d41da22a9587
+	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20250301202403-da16c1255728
+)
@@ -0,0 +1,66 @@
+package history
+
+import "reflect"
+
+// State is a snapshot of the edited data that can be put back in place.
+type State interface {
+	Restore()
+}
+
+// command is an undoable change of the application state.
+type command struct {
+	do   func()
+	undo func()
+}
+
+// History keeps the edits that were done and undone, so that the user can
+// step back and forth through them. An edit is recorded as the snapshots
+// taken by Save before and after it.
+type History struct {
+	Save   func() State
+	done   []command
+	undone []command
+}
+
+// Commit records the change from before to the current state, if any. Any
+// undone edits are forgotten: after a new edit they cannot be redone.
+func (h *History) Commit(before State) {
+	after := h.Save()
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	h.done = append(h.done, command{after.Restore, before.Restore})
+	h.undone = nil
+}
+
+// Edit runs change as one step of the history.
+func (h *History) Edit(change func()) {
+	before := h.Save()
+	change()
+	h.Commit(before)
+}
+
+// Undo reverts the last edit. It returns false if there is nothing to undo.
+func (h *History) Undo() bool {
+	if len(h.done) == 0 {
+		return false
+	}
+	c := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	c.undo()
+	h.undone = append(h.undone, c)
+	return true
+}
+
+// Redo makes again the last undone edit. It returns false if there is
+// nothing to redo.
+func (h *History) Redo() bool {
+	if len(h.undone) == 0 {
+		return false
+	}
+	c := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	c.do()
+	h.done = append(h.done, c)
+	return true
+}
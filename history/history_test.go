@@ -0,0 +1,36 @@
+package history
+
+import "testing"
+
+type counter struct {
+	value *int
+	saved int
+}
+
+func (c counter) Restore() {
+	*c.value = c.saved
+}
+
+func TestHistory(t *testing.T) {
+	value := 0
+	h := History{Save: func() State { return counter{&value, value} }}
+	h.Edit(func() { value = 1 })
+	h.Edit(func() {})
+	h.Edit(func() { value = 2 })
+	if !h.Undo() || value != 1 {
+		t.Fatalf("after undo value = %d, want 1", value)
+	}
+	if !h.Undo() || value != 0 {
+		t.Fatalf("after the second undo value = %d, want 0: an edit changing nothing is not recorded", value)
+	}
+	if h.Undo() {
+		t.Fatal("undo with empty history succeeded")
+	}
+	if !h.Redo() || value != 1 {
+		t.Fatalf("after redo value = %d, want 1", value)
+	}
+	h.Edit(func() { value = 3 })
+	if h.Redo() {
+		t.Fatal("redo after a new edit succeeded")
+	}
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"math"
+	"os"
 	"runtime"
 
+	clip "github.com/MKondakova/Computer_graphics/clipping"
+	"github.com/MKondakova/Computer_graphics/drag"
+	"github.com/MKondakova/Computer_graphics/history"
+	"github.com/MKondakova/Computer_graphics/scene"
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
 )
@@ -15,111 +21,249 @@ const (
 	CLIPPING                 = 3
 	SIZE                     = 1000
 	ZONE_PADDING_COEFFICIENT = 0.2
-	ACCURACY                 = math.Sqrt2
 )
 
-type point struct {
-	x float64
-	y float64
-}
-
 var (
-	mouse     point
+	mouse     clip.Point
 	stage     int = PLOTTING_SEGMENTS
 	sizeX     int
 	sizeY     int
-	segments  [][2]point
-	points    []point
-	zoneLeft  float64
-	zoneRight float64
-	zoneCeil  float64
-	zoneFloor float64
+	segments  []clip.Segment
+	points    []clip.Point
+	zone      clip.Rect
+	clipperID int = 0
+	//многоугольник отсечения вместо прямоугольника zone
+	polygonWindow bool = false
+	clipPolygon   []clip.Point
+	inner         bool   = true
+	scenePath     string = "scene.json"
+
+	edits   history.History = history.History{Save: saveEdit}
+	dragged drag.Drag       = drag.Drag{Edits: &edits, Moved: pointMoved}
+	//стороны окна, которые тянет мышь
+	zoneSides  int
+	zoneAnchor clip.Point
+	zoneStart  clip.Rect
+	dragStart  history.State
+	//пошаговый просмотр деления средней точкой
+	tracing    bool = false
+	trace      []traceStep
+	traceIndex int
 )
 
-func clipping() {
-	tempSegments := makeSegments()
-	for _, segment := range tempSegments {
-		midpointClipping(segment, 1)
+// traceStep is a halving of the segment-th entered segment.
+type traceStep struct {
+	segment int
+	clip.Step
+}
+
+// editState is the part of the input that undo and redo bring back.
+type editState struct {
+	points      []clip.Point
+	segments    []clip.Segment
+	stage       int
+	zone        clip.Rect
+	clipPolygon []clip.Point
+	width       int
+	height      int
+}
+
+func saveEdit() history.State {
+	return editState{append([]clip.Point{}, points...), append([]clip.Segment{}, segments...), stage,
+		zone, append([]clip.Point{}, clipPolygon...), sizeX, sizeY}
+}
+
+func (s editState) Restore() {
+	points, segments, stage = append([]clip.Point{}, s.points...), append([]clip.Segment{}, s.segments...), s.stage
+	zone, clipPolygon = s.zone, append([]clip.Point{}, s.clipPolygon...)
+	scaleInput(s.width, s.height)
+	zoneSides = 0
+	dragged.Cancel()
+	if stage == CLIPPING {
+		clipping()
 	}
 }
 
-func midpointClipping(segment [2]point, count int) {
-	firstCode := getCode(segment[0])
-	secondCode := getCode(segment[1])
-	if firstCode+secondCode == 0 {
-		segments = append(segments, segment)
+func scalePoint(p *clip.Point, kx, ky float64) {
+	p.X, p.Y = p.X*kx, p.Y*ky
+}
+
+// scaleInput maps the points, the clipped segments and the clip window from
+// a width×height window to the current one.
+func scaleInput(width, height int) {
+	if width <= 0 || height <= 0 || (width == sizeX && height == sizeY) {
 		return
 	}
-	if firstCode&secondCode != 0 {
-		return
+	kx, ky := float64(sizeX)/float64(width), float64(sizeY)/float64(height)
+	for i := range points {
+		scalePoint(&points[i], kx, ky)
 	}
-	if count > 2 {
-		segments = append(segments, segment)
-		return
+	for i := range clipPolygon {
+		scalePoint(&clipPolygon[i], kx, ky)
 	}
-	firstPoint := segment[0]
-	if secondCode == 0 {
-		segment[1], segment[0] = firstPoint, segment[1]
-		count++
-		midpointClipping(segment, count)
-		return
+	for i := range segments {
+		scalePoint(&segments[i][0], kx, ky)
+		scalePoint(&segments[i][1], kx, ky)
 	}
-	for {
-		if math.Hypot(segment[0].x-segment[1].x, segment[0].y-segment[1].y) <= ACCURACY {
-			segment[1], segment[0] = firstPoint, segment[1]
-			count++
-			midpointClipping(segment, count)
-			return
+	zone = scaleRect(zone, kx, ky)
+}
+
+func scaleRect(r clip.Rect, kx, ky float64) clip.Rect {
+	return clip.Rect{Left: r.Left * kx, Top: r.Top * ky, Right: r.Right * kx, Bottom: r.Bottom * ky}
+}
+
+// clipping clips the entered segments by the clip window and returns the
+// number of steps the chosen clipper took. The steps are only counted for
+// internal clipping by the rectangle.
+func clipping() int {
+	segments = []clip.Segment{}
+	total := 0
+	for _, segment := range makeSegments() {
+		if polygonWindow {
+			segments = append(segments, clip.ClipPolygon(segment, clipPolygon, inner)...)
+			continue
+		}
+		if !inner {
+			segments = append(segments, clip.ClipConvexOutside(segment, zone.Polygon())...)
+			continue
 		}
-		midpoint := point{(segment[0].x + segment[1].x) / 2, (segment[0].y + segment[1].y) / 2}
-		memoizedPoint := segment[0]
-		segment[0] = midpoint
-		firstCode = getCode(segment[0])
-		if firstCode&secondCode != 0 {
-			segment[0], segment[1] = memoizedPoint, midpoint
+		clipped, ok, steps := clip.Clippers[clipperID].Clip(segment, zone)
+		if ok {
+			segments = append(segments, clipped)
+		}
+		total += steps
+	}
+	if tracing {
+		buildTrace()
+	}
+	return total
+}
+
+// buildTrace records the halvings of midpoint subdivision for every entered
+// segment, whichever clipper is chosen, including the ones that decide
+// whether a segment passes by a corner. Only internal clipping by the
+// rectangle is traced.
+func buildTrace() {
+	trace = []traceStep{}
+	if !polygonWindow && inner {
+		for i, segment := range makeSegments() {
+			_, _, steps := clip.MidpointTrace(segment, zone)
+			for _, step := range steps {
+				trace = append(trace, traceStep{i + 1, step})
+			}
 		}
 	}
+	if traceIndex >= len(trace) {
+		traceIndex = len(trace) - 1
+	}
+	if traceIndex < 0 {
+		traceIndex = 0
+	}
 }
-	
-func getCode(p point) int {
-	code := 0
-	if p.y > zoneFloor {
-		code++
+
+func changeTracing() {
+	tracing = !tracing
+	log.Println("midpoint trace:", tracing)
+	traceIndex = 0
+	if tracing && stage == CLIPPING {
+		clipping()
+	}
+}
+
+func changeTraceStep(delta int) {
+	if !tracing || len(trace) == 0 {
+		return
 	}
-	code *= 2
-	if p.x > zoneRight {
-		code++
+	traceIndex += delta
+	if traceIndex < 0 {
+		traceIndex = 0
 	}
-	code *= 2
-	if p.y < zoneCeil {
-		code++
+	if traceIndex >= len(trace) {
+		traceIndex = len(trace) - 1
 	}
-	code *= 2
-	if p.x < zoneLeft {
-		code++
+}
+
+// drawTrace shows the current halving: the sub-segment being halved and its
+// midpoint over the entered segments.
+func drawTrace() {
+	if !tracing || stage != CLIPPING || len(trace) == 0 {
+		return
+	}
+	step := trace[traceIndex]
+	gl.Color3d(0.3, 0.3, 0.3)
+	gl.Begin(gl.LINES)
+	for _, segment := range makeSegments() {
+		gl.Vertex2d(segment[0].X, segment[0].Y)
+		gl.Vertex2d(segment[1].X, segment[1].Y)
+	}
+	gl.End()
+	gl.Color3d(1, 0.6, 0)
+	gl.Begin(gl.LINES)
+	gl.Vertex2d(step.Segment[0].X, step.Segment[0].Y)
+	gl.Vertex2d(step.Segment[1].X, step.Segment[1].Y)
+	gl.End()
+	gl.PointSize(7)
+	gl.Begin(gl.POINTS)
+	gl.Vertex2d(step.Segment[0].X, step.Segment[0].Y)
+	gl.Vertex2d(step.Segment[1].X, step.Segment[1].Y)
+	gl.Color3d(1, 0, 0)
+	gl.Vertex2d(step.Midpoint.X, step.Midpoint.Y)
+	gl.End()
+	gl.Color3d(1, 1, 1)
+}
+
+func title() string {
+	if !tracing || stage != CLIPPING || len(trace) == 0 {
+		return "LAB_5"
 	}
-	return code
+	step := trace[traceIndex]
+	return fmt.Sprintf("LAB_5 | midpoint trace | segment %d, step %d/%d, count %d, codes %04b %04b, midpoint %04b",
+		step.segment, traceIndex+1, len(trace), step.Count, step.Codes[0], step.Codes[1], step.Codes[2])
 }
 
-func makeSegments() [][2]point {
-	temp := [][2]point{}
+func logClipping() {
+	steps := clipping()
+	if polygonWindow || !inner {
+		log.Println(len(segments), "visible parts")
+		return
+	}
+	log.Println(clip.Clippers[clipperID], "steps:", steps)
+}
+
+func makeSegments() []clip.Segment {
+	temp := []clip.Segment{}
 	for i := 0; i < len(points)/2; i++ {
-		temp = append(temp, [2]point{points[2*i], points[2*i+1]})
+		temp = append(temp, clip.Segment{points[2*i], points[2*i+1]})
 	}
 	return temp
 }
 
-func drawZone() {
-	zoneLeft = float64(sizeX) * ZONE_PADDING_COEFFICIENT
-	zoneRight = float64(sizeX) * (1 - ZONE_PADDING_COEFFICIENT)
-	zoneFloor = float64(sizeY) * (1 - ZONE_PADDING_COEFFICIENT)
-	zoneCeil = float64(sizeY) * ZONE_PADDING_COEFFICIENT
+func resetZone() {
+	zone = clip.Rect{
+		Left:   float64(sizeX) * ZONE_PADDING_COEFFICIENT,
+		Top:    float64(sizeY) * ZONE_PADDING_COEFFICIENT,
+		Right:  float64(sizeX) * (1 - ZONE_PADDING_COEFFICIENT),
+		Bottom: float64(sizeY) * (1 - ZONE_PADDING_COEFFICIENT),
+	}
+}
 
+func drawZone() {
+	if polygonWindow {
+		gl.Begin(gl.LINE_LOOP)
+		for _, p := range clipPolygon {
+			gl.Vertex2d(p.X, p.Y)
+		}
+		if stage == SEGMENTS_PLOTTED {
+			gl.Vertex2d(mouse.X, mouse.Y)
+		}
+		gl.End()
+		return
+	}
 	gl.Begin(gl.LINE_LOOP)
-	gl.Vertex2d(zoneLeft, zoneFloor)
-	gl.Vertex2d(zoneLeft, zoneCeil)
-	gl.Vertex2d(zoneRight, zoneCeil)
-	gl.Vertex2d(zoneRight, zoneFloor)
+	gl.Vertex2d(zone.Left, zone.Bottom)
+	gl.Vertex2d(zone.Left, zone.Top)
+	gl.Vertex2d(zone.Right, zone.Top)
+	gl.Vertex2d(zone.Right, zone.Bottom)
 	gl.End()
 }
 
@@ -127,24 +271,24 @@ func drawSegments() {
 	if stage == PLOTTING_SEGMENTS || stage == SEGMENTS_PLOTTED {
 		gl.Begin(gl.LINES)
 		for _, p := range points {
-			gl.Vertex2d(p.x, p.y)
+			gl.Vertex2d(p.X, p.Y)
 		}
 		if stage == PLOTTING_SEGMENTS {
-			gl.Vertex2d(mouse.x, mouse.y)
+			gl.Vertex2d(mouse.X, mouse.Y)
 		}
 		gl.End()
 	} else {
 		gl.Begin(gl.LINES)
 		for _, segment := range segments {
-			gl.Vertex2d(segment[0].x, segment[0].y)
-			gl.Vertex2d(segment[1].x, segment[1].y)
+			gl.Vertex2d(segment[0].X, segment[0].Y)
+			gl.Vertex2d(segment[1].X, segment[1].Y)
 		}
 		gl.End()
 	}
 }
 
 func cycleInit(w *glfw.Window) {
-	mouse.x, mouse.y = w.GetCursorPos()
+	mouse.X, mouse.Y = w.GetCursorPos()
 
 }
 func closeWindowCallback(w *glfw.Window) {
@@ -152,6 +296,11 @@ func closeWindowCallback(w *glfw.Window) {
 	w.SetShouldClose(true)
 }
 func sizeCallback(w *glfw.Window, width int, height int) {
+	//свёрнутое окно имеет нулевой размер
+	if width == 0 || height == 0 {
+		return
+	}
+	oldX, oldY := sizeX, sizeY
 	sizeX, sizeY = width, height
 	gl.MatrixMode(gl.PROJECTION)
 	gl.LoadIdentity()
@@ -159,25 +308,128 @@ func sizeCallback(w *glfw.Window, width int, height int) {
 
 	gl.Viewport(0, 0, int32(width), int32(height))
 
-	clear()
+	if oldX == 0 || oldY == 0 {
+		resetZone()
+		return
+	}
+	scaleInput(oldX, oldY)
+	if stage == CLIPPING {
+		clipping()
+	}
 }
 
 func changeStateCallback() {
+	if stage == SEGMENTS_PLOTTED && polygonWindow && len(clipPolygon) < 3 {
+		return
+	}
 	if len(points) >= 2 {
 		stage = stage + 1
 		if stage > CLIPPING {
 			stage = PLOTTING_SEGMENTS
 		}
 		if stage == CLIPPING {
-			clipping()
+			logClipping()
 		}
 		log.Println(stage)
 	}
 }
 
+func changeClipper() {
+	clipperID = (clipperID + 1) % len(clip.Clippers)
+	log.Println("clipper:", clip.Clippers[clipperID])
+	if stage == CLIPPING {
+		logClipping()
+	}
+}
+
+func changeWindowMode() {
+	polygonWindow = !polygonWindow
+	log.Println("polygon window:", polygonWindow)
+	if stage == CLIPPING && polygonWindow && len(clipPolygon) < 3 {
+		stage = SEGMENTS_PLOTTED
+	}
+	if stage == CLIPPING {
+		logClipping()
+	}
+}
+
+func changeInner() {
+	inner = !inner
+	log.Println("internal clipping:", inner)
+	if stage == CLIPPING {
+		logClipping()
+	}
+}
+
+func saveScene() {
+	s := scene.Scene{
+		Width:  sizeX,
+		Height: sizeY,
+		Stage:  stage,
+		Window: &scene.Window{Left: zone.Left, Top: zone.Top, Right: zone.Right, Bottom: zone.Bottom},
+	}
+	for _, segment := range makeSegments() {
+		s.Segments = append(s.Segments, [2][2]float64{{segment[0].X, segment[0].Y}, {segment[1].X, segment[1].Y}})
+	}
+	if len(points)%2 != 0 {
+		last := points[len(points)-1]
+		s.Points = [][2]float64{{last.X, last.Y}}
+	}
+	if polygonWindow {
+		for _, p := range clipPolygon {
+			s.ClipPolygon = append(s.ClipPolygon, [2]float64{p.X, p.Y})
+		}
+	}
+	if err := s.Save(scenePath); err != nil {
+		log.Println(err)
+		return
+	}
+	log.Println("saved", scenePath)
+}
+
+func loadScene() {
+	s, err := scene.Load(scenePath)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	points, segments = []clip.Point{}, []clip.Segment{}
+	for _, segment := range s.Segments {
+		points = append(points, clip.Point{X: segment[0][0], Y: segment[0][1]}, clip.Point{X: segment[1][0], Y: segment[1][1]})
+	}
+	for _, p := range s.Points {
+		points = append(points, clip.Point{X: p[0], Y: p[1]})
+	}
+	clipPolygon = []clip.Point{}
+	for _, p := range s.ClipPolygon {
+		clipPolygon = append(clipPolygon, clip.Point{X: p[0], Y: p[1]})
+	}
+	polygonWindow = len(clipPolygon) > 0
+	if s.Window != nil {
+		zone = clip.Rect{Left: s.Window.Left, Top: s.Window.Top, Right: s.Window.Right, Bottom: s.Window.Bottom}
+	}
+	stage = s.Stage
+	dragged.Cancel()
+	scaleInput(s.Width, s.Height)
+	if s.Window == nil {
+		resetZone()
+	}
+	if stage < PLOTTING_SEGMENTS || stage > CLIPPING || len(points) < 2 {
+		stage = PLOTTING_SEGMENTS
+	}
+	if stage == CLIPPING && polygonWindow && len(clipPolygon) < 3 {
+		stage = SEGMENTS_PLOTTED
+	}
+	if stage == CLIPPING {
+		clipping()
+	}
+	log.Println("loaded", scenePath)
+}
+
 func clear() {
-	segments = [][2]point{}
-	points = []point{}
+	segments = []clip.Segment{}
+	points = []clip.Point{}
+	clipPolygon = []clip.Point{}
 	stage = PLOTTING_SEGMENTS
 }
 
@@ -187,10 +439,45 @@ func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action,
 			closeWindowCallback(w)
 		}
 		if key == glfw.KeySpace {
-			changeStateCallback()
+			edits.Edit(changeStateCallback)
 		}
 		if key == glfw.KeyDelete {
-			clear()
+			edits.Edit(clear)
+		}
+		if key == glfw.KeyC {
+			changeClipper()
+		}
+		if key == glfw.KeyW {
+			changeWindowMode()
+		}
+		if key == glfw.KeyI {
+			changeInner()
+		}
+		if key == glfw.KeyR && !polygonWindow {
+			edits.Edit(loadZone)
+		}
+		if key == glfw.KeyP {
+			saveScene()
+		}
+		if key == glfw.KeyL {
+			edits.Edit(loadScene)
+		}
+		if key == glfw.KeyZ && mods&glfw.ModControl != 0 && !edits.Undo() {
+			log.Println("nothing to undo")
+		}
+		if key == glfw.KeyY && mods&glfw.ModControl != 0 && !edits.Redo() {
+			log.Println("nothing to redo")
+		}
+		if key == glfw.KeyT {
+			changeTracing()
+		}
+	}
+	if action == glfw.Press || action == glfw.Repeat {
+		if key == glfw.KeyRight {
+			changeTraceStep(1)
+		}
+		if key == glfw.KeyLeft {
+			changeTraceStep(-1)
 		}
 	}
 }
@@ -198,7 +485,11 @@ func makePoint(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod
 	if stage == PLOTTING_SEGMENTS {
 		x, y := w.GetCursorPos()
 		log.Println(x, y, " :mouse")
-		points = append(points, point{x, y})
+		points = append(points, clip.Point{X: x, Y: y})
+	}
+	if stage == SEGMENTS_PLOTTED && polygonWindow {
+		x, y := w.GetCursorPos()
+		clipPolygon = append(clipPolygon, clip.Point{X: x, Y: y})
 	}
 }
 
@@ -206,14 +497,158 @@ func deletePoint(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mo
 	if stage == PLOTTING_SEGMENTS && len(points) > 0 {
 		points = points[:len(points)-1]
 	}
+	if stage == SEGMENTS_PLOTTED && polygonWindow && len(clipPolygon) > 0 {
+		clipPolygon = clipPolygon[:len(clipPolygon)-1]
+	}
+}
+
+// vertices returns the entered points and the vertices of the clip polygon
+// for dragging.
+func vertices() []drag.Vertex {
+	vs := []drag.Vertex{}
+	add := func(pts []clip.Point) {
+		for i := range pts {
+			vs = append(vs, drag.Vertex{X: &pts[i].X, Y: &pts[i].Y})
+		}
+	}
+	add(points)
+	if polygonWindow {
+		add(clipPolygon)
+	}
+	return vs
+}
+
+func grabPoint(w *glfw.Window) bool {
+	x, y := w.GetCursorPos()
+	return dragged.Grab(x, y, vertices())
+}
+
+func pointMoved() {
+	if stage == CLIPPING {
+		clipping()
+	}
+}
+
+// grabZone starts dragging the clip window: its edges or corners near the
+// cursor resize it, inside of it the cursor moves it and outside of it a new
+// window is dragged out.
+func grabZone(w *glfw.Window) {
+	x, y := w.GetCursorPos()
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) <= drag.Radius
+	}
+	dragStart = edits.Save()
+	zoneAnchor = clip.Point{X: x, Y: y}
+	if x < zone.Left-drag.Radius || x > zone.Right+drag.Radius || y < zone.Top-drag.Radius || y > zone.Bottom+drag.Radius {
+		zone = clip.Rect{Left: x, Top: y, Right: x, Bottom: y}
+		zoneSides = clip.RightSide | clip.BottomSide
+	} else {
+		zoneSides = 0
+		if near(x, zone.Left) {
+			zoneSides |= clip.LeftSide
+		} else if near(x, zone.Right) {
+			zoneSides |= clip.RightSide
+		}
+		if near(y, zone.Top) {
+			zoneSides |= clip.TopSide
+		} else if near(y, zone.Bottom) {
+			zoneSides |= clip.BottomSide
+		}
+		if zoneSides == 0 {
+			zoneSides = clip.LeftSide | clip.TopSide | clip.RightSide | clip.BottomSide
+		}
+	}
+	zoneStart = zone
+}
+
+// dragZone shifts the grabbed sides of the window by the cursor movement.
+func dragZone(w *glfw.Window, x float64, y float64) {
+	if zoneSides == 0 {
+		return
+	}
+	dx, dy := x-zoneAnchor.X, y-zoneAnchor.Y
+	r := zoneStart
+	if zoneSides&clip.LeftSide != 0 {
+		r.Left += dx
+	}
+	if zoneSides&clip.RightSide != 0 {
+		r.Right += dx
+	}
+	if zoneSides&clip.TopSide != 0 {
+		r.Top += dy
+	}
+	if zoneSides&clip.BottomSide != 0 {
+		r.Bottom += dy
+	}
+	zone = r.Normalized()
+	if stage == CLIPPING {
+		clipping()
+	}
+}
+
+// releaseZone ends dragging the clip window. A window of zero area, as a
+// click without dragging makes, is dropped and the old one is brought back.
+func releaseZone() {
+	if zoneSides == 0 {
+		return
+	}
+	zoneSides = 0
+	if zone.Left == zone.Right || zone.Top == zone.Bottom {
+		log.Println("empty clip window")
+		dragStart.Restore()
+		return
+	}
+	edits.Commit(dragStart)
+}
+
+func loadZone() {
+	s, err := scene.Load(scenePath)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if s.Window == nil {
+		log.Println(scenePath, "has no clip window")
+		return
+	}
+	r := clip.Rect{Left: s.Window.Left, Top: s.Window.Top, Right: s.Window.Right, Bottom: s.Window.Bottom}.Normalized()
+	if s.Width > 0 && s.Height > 0 {
+		r = scaleRect(r, float64(sizeX)/float64(s.Width), float64(sizeY)/float64(s.Height))
+	}
+	if r.Left == r.Right || r.Top == r.Bottom {
+		log.Println(scenePath, "has an empty clip window")
+		return
+	}
+	zone = r
+	if stage == CLIPPING {
+		clipping()
+	}
+	log.Println("clip window:", zone)
+}
+
+func cursorCallback(w *glfw.Window, x float64, y float64) {
+	dragged.Move(x, y)
+	dragZone(w, x, y)
 }
 
 func mouseCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
-	if button == glfw.MouseButtonLeft && action == glfw.Press {
-		makePoint(w, button, action, mod)
+	if button == glfw.MouseButtonLeft && action == glfw.Release {
+		dragged.Release()
+		releaseZone()
+	}
+	if button == glfw.MouseButtonLeft && action == glfw.Press && mod&glfw.ModControl != 0 && !polygonWindow {
+		grabZone(w)
+		return
+	}
+	if button == glfw.MouseButtonLeft && action == glfw.Press && !grabPoint(w) {
+		edits.Edit(func() {
+			makePoint(w, button, action, mod)
+		})
 	}
 	if button == glfw.MouseButtonRight && action == glfw.Press {
-		deletePoint(w, button, action, mod)
+		edits.Edit(func() {
+			deletePoint(w, button, action, mod)
+		})
 	}
 
 }
@@ -248,16 +683,24 @@ func main() {
 	window.SetFramebufferSizeCallback(glfw.FramebufferSizeCallback(sizeCallback))
 	window.SetKeyCallback(glfw.KeyCallback(keyCallback))
 	window.SetMouseButtonCallback(glfw.MouseButtonCallback(mouseCallback))
+	window.SetCursorPosCallback(glfw.CursorPosCallback(cursorCallback))
 
 	w, h := window.GetFramebufferSize()
 	sizeCallback(window, w, h)
 
+	if len(os.Args) > 1 {
+		scenePath = os.Args[1]
+		loadScene()
+	}
+
 	for !window.ShouldClose() {
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 		cycleInit(window)
 
 		drawZone()
+		drawTrace()
 		drawSegments()
+		window.SetTitle(title())
 
 		glfw.WaitEvents()
 		window.SwapBuffers()
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"image"
+	"image/color"
 	"log"
-	"math"
+	"os"
 	"runtime"
-	"sort"
+	"time"
 	"unsafe"
 
+	clip "github.com/MKondakova/Computer_graphics/clipping"
+	"github.com/MKondakova/Computer_graphics/drag"
+	"github.com/MKondakova/Computer_graphics/history"
+	"github.com/MKondakova/Computer_graphics/raster"
+	"github.com/MKondakova/Computer_graphics/scene"
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
 )
@@ -17,151 +24,342 @@ const (
 	RASTERISATION    = 3
 	FILTRATION       = 4
 	SIZE             = 1000
-)
+	AA_SAMPLES       = 4
 
-type point struct {
-	x float64
-	y float64
-}
+	NO_CLIPPING        = 0
+	SUTHERLAND_HODGMAN = 1
+	WEILER_ATHERTON    = 2
+)
 
 var (
-	mouse      point
-	stage      int = BUILDING_POLYGON
-	points     []point
-	sizeX      int
-	sizeY      int
-	pixels     []uint8
-	tempPixels []uint8
-	list       map[int][]int
-	edges      [][2]point
+	mouse    raster.Point
+	stage    int = BUILDING_POLYGON
+	polygons []raster.Polygon
+	contours [][]raster.Point
+	points   []raster.Point
+	colorID  int = 0
+	sizeX    int
+	sizeY    int
+	canvas   *raster.Framebuffer
+	rule     raster.FillRule = raster.EvenOdd
+	smooth   bool            = false
+	kernel   int             = 0
+	border   raster.Border   = raster.Clamp
+
+	convention  raster.Convention = raster.Inclusive
+	showOverlap bool              = false
+
+	showOutline bool                 = false
+	lineAlg     raster.LineAlgorithm = raster.DDALine
+
+	bucketMode   bool                = false
+	connectivity raster.Connectivity = raster.FourConnected
+
+	//первый многоугольник отсекается вторым
+	clipMode int = NO_CLIPPING
+
+	edits history.History = history.History{Save: saveEdit}
+
+	dragged drag.Drag = drag.Drag{Edits: &edits, Moved: vertexMoved}
+
+	scenePath string = "scene.json"
+
+	background color.RGBA   = color.RGBA{0, 0, 0, 255}
+	palette    []color.RGBA = []color.RGBA{
+		{0, 0, 255, 255},
+		{255, 0, 0, 160},
+		{0, 255, 0, 160},
+		{255, 255, 0, 160},
+		{255, 0, 255, 160},
+	}
 )
 
-func makeEdges() {
-	edges = make([][2]point, len(points))
-	for i, p := range points {
-		nextP := points[(i+1)%len(points)]
-		edges[i] = [2]point{p, nextP}
-	}
+// editState is the part of the input that undo and redo bring back.
+type editState struct {
+	polygons []raster.Polygon
+	contours [][]raster.Point
+	points   []raster.Point
+	colorID  int
+	stage    int
+	width    int
+	height   int
 }
 
-func isExtrema(y, y1, y2 float64) bool {
-	return (y > y1 && y > y2) || (y < y1 && y < y2)
+func copyContours(src [][]raster.Point) [][]raster.Point {
+	dst := make([][]raster.Point, len(src))
+	for i, contour := range src {
+		dst[i] = append([]raster.Point{}, contour...)
+	}
+	return dst
 }
 
-func vertexCountTwice(i, j int) bool {
-	l := len(edges)
-	return isExtrema(
-		edges[i][j].y,
-		edges[i][(j+1)%2].y,
-		edges[(i-1+l)%l][j].y)
+func copyPolygons(src []raster.Polygon) []raster.Polygon {
+	dst := make([]raster.Polygon, len(src))
+	for i, polygon := range src {
+		dst[i] = raster.Polygon{Contours: copyContours(polygon.Contours), Color: polygon.Color}
+	}
+	return dst
 }
 
-func addToList(x, y float64) {
-	list[int(math.Floor(y))] = append(list[int(math.Floor(y))], int(math.Floor(x)))
+func saveEdit() history.State {
+	return editState{copyPolygons(polygons), copyContours(contours), append([]raster.Point{}, points...), colorID, stage,
+		sizeX, sizeY}
 }
 
-func DDA() {
-	for i, edge := range edges {
-		if vertexCountTwice(i, 0) {
-			addToList(edge[0].x, edge[0].y)
-		}
-		addToList(edge[1].x, edge[1].y)
+func (s editState) Restore() {
+	polygons, contours, points = copyPolygons(s.polygons), copyContours(s.contours), append([]raster.Point{}, s.points...)
+	colorID, stage = s.colorID, s.stage
+	dragged.Cancel()
+	scaleInput(s.width, s.height)
+	if stage == POLYGON_BUILDED && bucketMode {
+		outlineCanvas()
+	}
+	updateStage()
+}
 
-		dy := edge[1].y - edge[0].y //разница между вершинами
-		dx := edge[1].x - edge[0].x
+func scalePoints(pts []raster.Point, kx, ky float64) {
+	for i := range pts {
+		pts[i] = raster.Point{X: pts[i].X * kx, Y: pts[i].Y * ky}
+	}
+}
 
-		if dy == 0 {
-			continue
+// scaleInput maps the entered vertices from a width×height window to the
+// current one.
+func scaleInput(width, height int) {
+	if width <= 0 || height <= 0 || (width == sizeX && height == sizeY) {
+		return
+	}
+	kx, ky := float64(sizeX)/float64(width), float64(sizeY)/float64(height)
+	for _, polygon := range polygons {
+		for _, contour := range polygon.Contours {
+			scalePoints(contour, kx, ky)
 		}
+	}
+	for _, contour := range contours {
+		scalePoints(contour, kx, ky)
+	}
+	scalePoints(points, kx, ky)
+}
 
-		count := int(math.Ceil(math.Abs(dy)))
-		dy = dy / float64(count) //дельта отступа
-		dx = dx / float64(count)
+func closeContour() {
+	if len(points) > 2 {
+		contours = append(contours, points)
+		points = []raster.Point{}
+	}
+}
 
-		checkEndFunc := func(i float64) bool {
-			if dy > 0 {
-				return edge[0].y+i*dy < edge[1].y
-			} else {
-				return edge[0].y+i*dy > edge[1].y
-			}
-		}
+func closePolygon() {
+	closeContour()
+	if len(contours) > 0 {
+		polygons = append(polygons, raster.Polygon{Contours: contours, Color: palette[colorID]})
+		contours = [][]raster.Point{}
+		colorID = (colorID + 1) % len(palette)
+	}
+}
+
+func allPolygons() []raster.Polygon {
+	if len(contours) == 0 {
+		return polygons
+	}
+	return append(polygons[:len(polygons):len(polygons)], raster.Polygon{Contours: contours, Color: palette[colorID]})
+}
 
-		for i := float64(1); checkEndFunc(i); i++ {
-			addToList(edge[0].x+i*dx, edge[0].y+i*dy)
+func toClip(contours [][]raster.Point) [][]clip.Point {
+	result := make([][]clip.Point, len(contours))
+	for i, contour := range contours {
+		for _, p := range contour {
+			result[i] = append(result[i], clip.Point{X: p.X, Y: p.Y})
 		}
 	}
+	return result
 }
 
-func drawLine(y, x1, x2 int) {
-	for i := x1; i <= x2; i++ {
-		pixels[(sizeY-y)*sizeX+i] = 255
+func fromClip(contours [][]clip.Point) [][]raster.Point {
+	result := make([][]raster.Point, len(contours))
+	for i, contour := range contours {
+		for _, p := range contour {
+			result[i] = append(result[i], raster.Point{X: p.X, Y: p.Y})
+		}
 	}
+	return result
 }
 
-func fill() {
-	for y := range list {
-		sort.Ints(list[y])
-		for i := 0; i < len(list[y]); i += 2 {
-			drawLine(y, list[y][i], list[y][i+1])
+// shownPolygons returns the polygons to fill: all of them or, in the
+// polygon clipping mode, the first one clipped by the second.
+func shownPolygons() []raster.Polygon {
+	all := allPolygons()
+	if clipMode == NO_CLIPPING || len(all) < 2 {
+		return all
+	}
+	subject, window := toClip(all[0].Contours), toClip(all[1].Contours)
+	var result [][]clip.Point
+	if clipMode == SUTHERLAND_HODGMAN {
+		if len(window) > 1 || !clip.IsConvex(window[0]) {
+			log.Println("Sutherland-Hodgman needs a convex clip polygon")
+			return all
 		}
+		result = clip.SutherlandHodgman(subject, window[0])
+	} else {
+		result = clip.WeilerAtherton(subject, window)
 	}
+	log.Println("clipped contours:", len(result))
+	return []raster.Polygon{{Contours: fromClip(result), Color: all[0].Color}}
 }
 
 func rasterisation() {
-	pixels = make([]uint8, sizeY*sizeX)
-	list = make(map[int][]int)
-	makeEdges()
-	DDA()
-	fill()
-}
-
-func getNeighborsSum(i, j int) (int, int) {
-	result := int(0)
-	counter := int(0)
-	for k := i - 1; k-(i-1) < 3; k++ {
-		for l := j - 1; l-(j-1) < 3; l++ {
-			if k >= 0 && k < sizeY && l >= 0 && l < sizeX {
-				result += int(pixels[sizeX*k+l])
-				counter++
+	canvas = raster.NewFramebuffer(sizeX, sizeY, background)
+	opts := raster.Options{Rule: rule, Convention: convention}
+	if clipMode != NO_CLIPPING {
+		//результат отсечения читается по правилу чётности
+		opts.Rule = raster.EvenOdd
+	}
+	if showOverlap {
+		overlap(opts)
+		return
+	}
+	if smooth {
+		opts.Samples = AA_SAMPLES
+	}
+	raster.FillPolygons(canvas, shownPolygons(), opts)
+}
+
+// overlap paints every pixel by the number of polygons covering it: green
+// for one, red for more. Gaps, pixels no polygon covers although their
+// centres are inside one of them, are blue.
+func overlap(opts raster.Options) {
+	counts := make([]int, sizeX*sizeY)
+	inside := make([]bool, sizeX*sizeY)
+	mask := raster.New(sizeX, sizeY)
+	//центры пикселей решают, что лежит внутри объединения
+	centres := opts
+	centres.Convention = raster.TopLeft
+	for _, polygon := range shownPolygons() {
+		mask.Clear()
+		raster.Fill(mask, polygon.Contours, opts)
+		for i, v := range mask.Pix {
+			if v > 0 {
+				counts[i]++
+			}
+		}
+		mask.Clear()
+		raster.Fill(mask, polygon.Contours, centres)
+		for i, v := range mask.Pix {
+			if v > 0 {
+				inside[i] = true
 			}
 		}
 	}
-	result -= int(pixels[sizeX*i+j])
-	counter--
-	return result, counter
+	overlapping, gaps := 0, 0
+	for y := 0; y < sizeY; y++ {
+		for x := 0; x < sizeX; x++ {
+			i := (sizeY-1-y)*sizeX + x
+			switch count := counts[i]; {
+			case count == 0 && inside[i]:
+				canvas.Set(x, y, color.RGBA{0, 90, 255, 255})
+				gaps++
+			case count == 1:
+				canvas.Set(x, y, color.RGBA{0, 160, 0, 255})
+			case count > 1:
+				canvas.Set(x, y, color.RGBA{255, 0, 0, 255})
+				overlapping++
+			}
+		}
+	}
+	log.Println("overlapping pixels:", overlapping, "gaps:", gaps)
+}
+
+func updateStage() {
+	if stage >= RASTERISATION {
+		rasterisation()
+	}
+	if stage == FILTRATION {
+		filtrate()
+	}
 }
 
 func filtrate() {
-	tempPixels = make([]uint8, sizeY*sizeX)
-	for i := 0; i < sizeY; i++ {
-		for j := 0; j < sizeX; j++ {
-			sum, counter := getNeighborsSum(i, j)
-			tempPixels[i*sizeX+j] = uint8(sum / counter)
+	raster.ConvolveRGBA(canvas, raster.Kernels[kernel], border)
+}
+
+func outlineRaster() *raster.Raster {
+	outline := raster.New(sizeX, sizeY)
+	for _, polygon := range allPolygons() {
+		for _, contour := range polygon.Contours {
+			raster.DrawOutline(outline, contour, lineAlg)
 		}
 	}
-	pixels = tempPixels
+	for i := 1; i < len(points); i++ {
+		raster.DrawSegment(outline, points[i-1], points[i], lineAlg)
+	}
+	return outline
+}
+
+func drawOutline() {
+	outline := outlineRaster()
+	gl.DrawPixels(int32(outline.Width), int32(outline.Height), gl.BLUE, gl.UNSIGNED_BYTE, unsafe.Pointer(&outline.Pix[0]))
+	if stage == BUILDING_POLYGON && len(points) > 0 {
+		gl.Begin(gl.LINE_STRIP)
+		gl.Vertex2d(points[len(points)-1].X, points[len(points)-1].Y)
+		gl.Vertex2d(mouse.X, mouse.Y)
+		gl.Vertex2d(points[0].X, points[0].Y)
+		gl.End()
+	}
+}
+
+// outlineCanvas puts the outline into the colour buffer, ready for the
+// paint bucket.
+func outlineCanvas() {
+	canvas = raster.NewFramebuffer(sizeX, sizeY, background)
+	canvas.Composite(outlineRaster(), color.RGBA{255, 255, 255, 255})
+}
+
+func bucketFill(w *glfw.Window) {
+	x, y := w.GetCursorPos()
+	painted := raster.FloodFill(canvas, int(x), int(y), palette[colorID], connectivity)
+	mask := raster.New(sizeX, sizeY)
+	for _, polygon := range allPolygons() {
+		raster.Fill(mask, polygon.Contours, raster.Options{Rule: rule})
+	}
+	lit, _ := mask.Count()
+	log.Println("bucket:", painted, "pixels, scanline fill:", lit, "pixels")
 }
 
 func drawPolygon() {
-	if stage == BUILDING_POLYGON || stage == POLYGON_BUILDED {
+	if stage == POLYGON_BUILDED && bucketMode {
+		gl.DrawPixels(int32(canvas.Width), int32(canvas.Height), gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&canvas.Pix[0]))
+	} else if (stage == BUILDING_POLYGON || stage == POLYGON_BUILDED) && showOutline {
+		drawOutline()
+	} else if stage == BUILDING_POLYGON || stage == POLYGON_BUILDED {
+		for _, polygon := range allPolygons() {
+			gl.Color3ub(polygon.Color.R, polygon.Color.G, polygon.Color.B)
+			for _, contour := range polygon.Contours {
+				gl.Begin(gl.LINE_LOOP)
+				for _, p := range contour {
+					gl.Vertex2d(p.X, p.Y)
+				}
+				gl.End()
+			}
+		}
+		gl.Color3ub(palette[colorID].R, palette[colorID].G, palette[colorID].B)
 		if len(points) > 0 {
 			gl.Begin(gl.LINE_LOOP)
 			for _, p := range points {
-				gl.Vertex2d(p.x, p.y)
+				gl.Vertex2d(p.X, p.Y)
 			}
 			if stage == BUILDING_POLYGON {
-				gl.Vertex2d(mouse.x, mouse.y)
+				gl.Vertex2d(mouse.X, mouse.Y)
 			}
 			gl.End()
 		}
+		gl.Color3d(1, 1, 1)
 	} else {
-		gl.DrawPixels(int32(sizeX), int32(sizeY), gl.BLUE, gl.UNSIGNED_BYTE, unsafe.Pointer(&pixels[0]))
+		gl.DrawPixels(int32(canvas.Width), int32(canvas.Height), gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&canvas.Pix[0]))
 
 	}
 }
 
 func cycleInit(w *glfw.Window) {
-	mouse.x, mouse.y = w.GetCursorPos()
+	mouse.X, mouse.Y = w.GetCursorPos()
 
 }
 func closeWindowCallback(w *glfw.Window) {
@@ -169,6 +367,11 @@ func closeWindowCallback(w *glfw.Window) {
 	w.SetShouldClose(true)
 }
 func sizeCallback(w *glfw.Window, width int, height int) {
+	//свёрнутое окно имеет нулевой размер
+	if width == 0 || height == 0 {
+		return
+	}
+	oldX, oldY := sizeX, sizeY
 	sizeX, sizeY = width, height
 	gl.MatrixMode(gl.PROJECTION)
 	gl.LoadIdentity()
@@ -176,17 +379,25 @@ func sizeCallback(w *glfw.Window, width int, height int) {
 
 	gl.Viewport(0, 0, int32(width), int32(height))
 
-	points = []point{}
-	pixels = []uint8{}
-	stage = BUILDING_POLYGON
+	scaleInput(oldX, oldY)
+	if stage == POLYGON_BUILDED && bucketMode {
+		outlineCanvas()
+	}
+	updateStage()
 }
 
 func changeStateCallback() {
-	if len(points) > 2 {
+	if stage == BUILDING_POLYGON {
+		closeContour()
+	}
+	if len(contours) > 0 || len(polygons) > 0 {
 		stage = stage + 1
 		if stage > FILTRATION {
 			stage = BUILDING_POLYGON
 		}
+		if stage == POLYGON_BUILDED && bucketMode {
+			outlineCanvas()
+		}
 		if stage == RASTERISATION {
 			rasterisation()
 		}
@@ -196,8 +407,177 @@ func changeStateCallback() {
 		log.Println(stage)
 	}
 }
+func changeFillRule() {
+	rule = (rule + 1) % 2
+	log.Println("fill rule:", rule)
+	updateStage()
+}
+
+func changeSmoothing() {
+	smooth = !smooth
+	log.Println("antialiasing:", smooth)
+	updateStage()
+}
+
+func changeConvention() {
+	convention = (convention + 1) % 2
+	log.Println("convention:", convention)
+	updateStage()
+}
+
+func changeOverlapView() {
+	showOverlap = !showOverlap
+	log.Println("overlap view:", showOverlap)
+	updateStage()
+}
+
+func changeClipMode() {
+	clipMode = (clipMode + 1) % 3
+	log.Println("polygon clipping:", []string{"off", "Sutherland-Hodgman", "Weiler-Atherton"}[clipMode])
+	updateStage()
+}
+
+func changeBucketMode() {
+	bucketMode = !bucketMode
+	log.Println("paint bucket:", bucketMode, connectivity)
+	if stage == POLYGON_BUILDED && bucketMode {
+		outlineCanvas()
+	}
+}
+
+func changeConnectivity(conn raster.Connectivity) {
+	connectivity = conn
+	log.Println("connectivity:", connectivity)
+}
+
+func changeKernel() {
+	kernel = (kernel + 1) % len(raster.Kernels)
+	log.Println("kernel:", raster.Kernels[kernel].Name)
+	updateStage()
+}
+
+func changeBorder() {
+	border = (border + 1) % 3
+	log.Println("border:", border)
+	updateStage()
+}
+
+func changeLineAlgorithm() {
+	if !showOutline {
+		showOutline, lineAlg = true, raster.DDALine
+	} else if lineAlg == raster.WuLine {
+		showOutline = false
+		log.Println("outline: OpenGL")
+		return
+	} else {
+		lineAlg++
+	}
+	lit, sum := outlineRaster().Count()
+	log.Println("outline:", lineAlg, "pixels:", lit, "intensity:", sum)
+}
+
+func changeColor() {
+	colorID = (colorID + 1) % len(palette)
+	log.Println("color:", palette[colorID])
+}
+
+func export() {
+	var img image.Image
+	if stage >= RASTERISATION || (stage == POLYGON_BUILDED && bucketMode) {
+		img = canvas.Image()
+	} else {
+		img = outlineRaster().Image()
+	}
+	name := "lab4_" + time.Now().Format("20060102_150405")
+	for _, ext := range []string{".png", ".ppm"} {
+		if err := raster.SaveImage(name+ext, img); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	log.Println("saved", name)
+}
+
+func toPairs(pts []raster.Point) [][2]float64 {
+	pairs := make([][2]float64, len(pts))
+	for i, p := range pts {
+		pairs[i] = [2]float64{p.X, p.Y}
+	}
+	return pairs
+}
+
+func fromPairs(pairs [][2]float64) []raster.Point {
+	pts := make([]raster.Point, len(pairs))
+	for i, v := range pairs {
+		pts[i] = raster.Point{X: v[0], Y: v[1]}
+	}
+	return pts
+}
+
+func saveScene() {
+	s := scene.Scene{Width: sizeX, Height: sizeY, Stage: stage, Points: toPairs(points), Color: colorID}
+	for _, contour := range contours {
+		s.Contours = append(s.Contours, toPairs(contour))
+	}
+	for _, polygon := range polygons {
+		c := polygon.Color
+		p := scene.Polygon{Color: []int{int(c.R), int(c.G), int(c.B), int(c.A)}}
+		for _, contour := range polygon.Contours {
+			p.Contours = append(p.Contours, toPairs(contour))
+		}
+		s.Polygons = append(s.Polygons, p)
+	}
+	if err := s.Save(scenePath); err != nil {
+		log.Println(err)
+		return
+	}
+	log.Println("saved", scenePath)
+}
+
+func loadScene() {
+	s, err := scene.Load(scenePath)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	polygons, contours, points = []raster.Polygon{}, [][]raster.Point{}, fromPairs(s.Points)
+	for _, contour := range s.Contours {
+		contours = append(contours, fromPairs(contour))
+	}
+	for _, p := range s.Polygons {
+		polygon := raster.Polygon{Color: raster.DefaultColor}
+		if len(p.Color) >= 3 {
+			polygon.Color = color.RGBA{uint8(p.Color[0]), uint8(p.Color[1]), uint8(p.Color[2]), 255}
+		}
+		if len(p.Color) == 4 {
+			polygon.Color.A = uint8(p.Color[3])
+		}
+		for _, contour := range p.Contours {
+			polygon.Contours = append(polygon.Contours, fromPairs(contour))
+		}
+		polygons = append(polygons, polygon)
+	}
+	colorID, stage = 0, s.Stage
+	if s.Color >= 0 && s.Color < len(palette) {
+		colorID = s.Color
+	}
+	if stage < BUILDING_POLYGON || stage > FILTRATION {
+		stage = BUILDING_POLYGON
+	}
+	canvas = nil
+	dragged.Cancel()
+	scaleInput(s.Width, s.Height)
+	if stage == POLYGON_BUILDED && bucketMode {
+		outlineCanvas()
+	}
+	updateStage()
+	log.Println("loaded", scenePath)
+}
+
 func clear() {
-	points = []point{}
+	polygons = []raster.Polygon{}
+	contours = [][]raster.Point{}
+	points = []raster.Point{}
 }
 
 func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
@@ -206,10 +586,67 @@ func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action,
 			closeWindowCallback(w)
 		}
 		if key == glfw.KeySpace {
-			changeStateCallback()
+			edits.Edit(changeStateCallback)
 		}
 		if key == glfw.KeyDelete {
-			clear()
+			edits.Edit(clear)
+		}
+		if key == glfw.KeyEnter && stage == BUILDING_POLYGON {
+			edits.Edit(closeContour)
+		}
+		if key == glfw.KeyTab && stage == BUILDING_POLYGON {
+			edits.Edit(closePolygon)
+		}
+		if key == glfw.KeyC && stage == BUILDING_POLYGON {
+			edits.Edit(changeColor)
+		}
+		if key == glfw.KeyP {
+			saveScene()
+		}
+		if key == glfw.KeyL {
+			edits.Edit(loadScene)
+		}
+		if key == glfw.KeyZ && mods&glfw.ModControl != 0 && !edits.Undo() {
+			log.Println("nothing to undo")
+		}
+		if key == glfw.KeyY && mods&glfw.ModControl != 0 && !edits.Redo() {
+			log.Println("nothing to redo")
+		}
+		if key == glfw.KeyW {
+			changeFillRule()
+		}
+		if key == glfw.KeyA {
+			changeSmoothing()
+		}
+		if key == glfw.KeyF {
+			changeKernel()
+		}
+		if key == glfw.KeyB {
+			changeBorder()
+		}
+		if key == glfw.KeyD {
+			changeLineAlgorithm()
+		}
+		if key == glfw.KeyE {
+			export()
+		}
+		if key == glfw.KeyT {
+			changeConvention()
+		}
+		if key == glfw.KeyO {
+			changeOverlapView()
+		}
+		if key == glfw.KeyX {
+			changeClipMode()
+		}
+		if key == glfw.KeyK {
+			changeBucketMode()
+		}
+		if key == glfw.Key4 {
+			changeConnectivity(raster.FourConnected)
+		}
+		if key == glfw.Key8 {
+			changeConnectivity(raster.EightConnected)
 		}
 	}
 }
@@ -217,22 +654,83 @@ func makePoint(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod
 	if stage == BUILDING_POLYGON {
 		x, y := w.GetCursorPos()
 		log.Println(x, y, " :mouse")
-		points = append(points, point{x, y})
+		points = append(points, raster.Point{X: x, Y: y})
 	}
 }
 
 func deletePoint(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
+	if stage == BUILDING_POLYGON && len(points) == 0 && len(contours) == 0 && len(polygons) > 0 {
+		//открываем последний законченный многоугольник заново
+		last := polygons[len(polygons)-1]
+		contours, polygons = last.Contours, polygons[:len(polygons)-1]
+		for i, c := range palette {
+			if c == last.Color {
+				colorID = i
+			}
+		}
+	}
+	if stage == BUILDING_POLYGON && len(points) == 0 && len(contours) > 0 {
+		//открываем последний замкнутый контур заново
+		points = contours[len(contours)-1]
+		contours = contours[:len(contours)-1]
+	}
 	if stage == BUILDING_POLYGON && len(points) > 0 {
 		points = points[:len(points)-1]
 	}
 }
 
+// vertices returns all the entered vertices for dragging.
+func vertices() []drag.Vertex {
+	vs := []drag.Vertex{}
+	add := func(pts []raster.Point) {
+		for i := range pts {
+			vs = append(vs, drag.Vertex{X: &pts[i].X, Y: &pts[i].Y})
+		}
+	}
+	for _, polygon := range polygons {
+		for _, contour := range polygon.Contours {
+			add(contour)
+		}
+	}
+	for _, contour := range contours {
+		add(contour)
+	}
+	add(points)
+	return vs
+}
+
+func grabVertex(w *glfw.Window) bool {
+	x, y := w.GetCursorPos()
+	return dragged.Grab(x, y, vertices())
+}
+
+func vertexMoved() {
+	if stage == POLYGON_BUILDED && bucketMode {
+		outlineCanvas()
+	}
+	updateStage()
+}
+
+func cursorCallback(w *glfw.Window, x float64, y float64) {
+	dragged.Move(x, y)
+}
+
 func mouseCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
-	if button == glfw.MouseButtonLeft && action == glfw.Press {
-		makePoint(w, button, action, mod)
+	if button == glfw.MouseButtonLeft && action == glfw.Release {
+		dragged.Release()
+	}
+	if button == glfw.MouseButtonLeft && action == glfw.Press && !grabVertex(w) {
+		if stage == POLYGON_BUILDED && bucketMode {
+			bucketFill(w)
+		}
+		edits.Edit(func() {
+			makePoint(w, button, action, mod)
+		})
 	}
 	if button == glfw.MouseButtonRight && action == glfw.Press {
-		deletePoint(w, button, action, mod)
+		edits.Edit(func() {
+			deletePoint(w, button, action, mod)
+		})
 	}
 
 }
@@ -267,10 +765,16 @@ func main() {
 	window.SetFramebufferSizeCallback(glfw.FramebufferSizeCallback(sizeCallback))
 	window.SetKeyCallback(glfw.KeyCallback(keyCallback))
 	window.SetMouseButtonCallback(glfw.MouseButtonCallback(mouseCallback))
+	window.SetCursorPosCallback(glfw.CursorPosCallback(cursorCallback))
 
 	w, h := window.GetFramebufferSize()
 	sizeCallback(window, w, h)
 
+	if len(os.Args) > 1 {
+		scenePath = os.Args[1]
+		loadScene()
+	}
+
 	for !window.ShouldClose() {
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 		cycleInit(window)
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+
+	"github.com/MKondakova/Computer_graphics/raster"
+)
+
+var kernels = map[string]raster.Kernel{
+	"box3":      raster.Box3,
+	"gaussian3": raster.Gaussian3,
+	"gaussian5": raster.Gaussian5,
+	"sharpen":   raster.Sharpen,
+	"edge":      raster.EdgeDetect,
+}
+
+var rules = map[string]raster.FillRule{
+	"evenodd": raster.EvenOdd,
+	"nonzero": raster.NonZero,
+}
+
+var backends = map[string]raster.Backend{
+	"list": raster.OrderedEdgeList,
+	"aet":  raster.ActiveEdgeTable,
+}
+
+var conventions = map[string]raster.Convention{
+	"inclusive": raster.Inclusive,
+	"topleft":   raster.TopLeft,
+}
+
+var borders = map[string]raster.Border{
+	"clamp": raster.Clamp,
+	"wrap":  raster.Wrap,
+	"zero":  raster.Zero,
+}
+
+type config struct {
+	width   int
+	height  int
+	opts    raster.Options
+	filter  string
+	border  raster.Border
+	polygon string
+	output  string
+}
+
+func render(polygons []raster.Polygon, cfg config) *raster.Framebuffer {
+	canvas := raster.NewFramebuffer(cfg.width, cfg.height, color.RGBA{0, 0, 0, 255})
+	raster.FillPolygons(canvas, polygons, cfg.opts)
+	if cfg.filter != "" {
+		raster.ConvolveRGBA(canvas, kernels[cfg.filter], cfg.border)
+	}
+	return canvas
+}
+
+func parseFlags() (config, error) {
+	var cfg config
+	var rule, border, backend, convention string
+	flag.IntVar(&cfg.width, "width", 1000, "image width")
+	flag.IntVar(&cfg.height, "height", 1000, "image height")
+	flag.StringVar(&rule, "rule", "evenodd", "fill rule: evenodd or nonzero")
+	flag.StringVar(&backend, "backend", "list", "scan conversion: list (ordered edge list) or aet (active edge table)")
+	flag.StringVar(&convention, "convention", "inclusive", "boundary pixels: inclusive or topleft")
+	flag.IntVar(&cfg.opts.Samples, "aa", 0, "subsamples per pixel side for anti-aliasing, 0 to disable")
+	flag.StringVar(&cfg.filter, "filter", "", "post-filter: box3, gaussian3, gaussian5, sharpen or edge")
+	flag.StringVar(&border, "border", "clamp", "filter border: clamp, wrap or zero")
+	flag.StringVar(&cfg.output, "o", "out.png", "output image, .png or .ppm")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: polygon_sweep_cli [flags] polygons.(txt|json)")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var ok bool
+	if cfg.opts.Rule, ok = rules[rule]; !ok {
+		return cfg, fmt.Errorf("unknown fill rule %q", rule)
+	}
+	if cfg.opts.Backend, ok = backends[backend]; !ok {
+		return cfg, fmt.Errorf("unknown backend %q", backend)
+	}
+	if cfg.opts.Convention, ok = conventions[convention]; !ok {
+		return cfg, fmt.Errorf("unknown convention %q", convention)
+	}
+	if cfg.border, ok = borders[border]; !ok {
+		return cfg, fmt.Errorf("unknown border %q", border)
+	}
+	if _, ok = kernels[cfg.filter]; cfg.filter != "" && !ok {
+		return cfg, fmt.Errorf("unknown filter %q", cfg.filter)
+	}
+	if cfg.width <= 0 || cfg.height <= 0 {
+		return cfg, fmt.Errorf("bad size %dx%d", cfg.width, cfg.height)
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cfg.polygon = flag.Arg(0)
+	return cfg, nil
+}
+
+func main() {
+	cfg, err := parseFlags()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	polygons, err := raster.LoadPolygons(cfg.polygon)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := raster.SaveImage(cfg.output, render(polygons, cfg).Image()); err != nil {
+		log.Fatalln(err)
+	}
+}
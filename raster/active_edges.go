@@ -0,0 +1,208 @@
+package raster
+
+import (
+	"math"
+	"sort"
+)
+
+// activeEdge is an edge of the active edge table. It crosses scanlines yMin
+// up to yMax-1; x is its crossing with the current one.
+type activeEdge struct {
+	yMin int
+	yMax int
+	x    float64
+	edge [2]Point
+	dx   float64
+	dy   float64
+	// step is the DDA step that falls on the current scanline or, with the
+	// TopLeft convention, the estimated crossing of the edge with its centre.
+	step float64
+	dir  int
+}
+
+// start prepares e to be stepped from its first scanline.
+func (e *activeEdge) start(conv Convention) {
+	if conv == TopLeft {
+		top, bottom := e.edge[0], e.edge[1]
+		e.dx = (bottom.X - top.X) / (bottom.Y - top.Y)
+		e.step = top.X + (float64(e.yMin)+0.5-top.Y)*e.dx - e.dx
+		return
+	}
+	target := float64(e.yMin)
+	if e.dy < 0 {
+		target++
+	}
+	e.step = math.Max(1, math.Floor((target-e.edge[0].Y)/e.dy))
+}
+
+// advance moves e to scanline y, the one after the previous. With the
+// TopLeft convention the crossing is the first pixel whose centre is not to
+// the left of the edge, otherwise the pixel of the first DDA step that falls
+// on the scanline. The crossing is stepped from the previous one and then
+// settled exactly, so that it does not drift and is the same the ordered
+// edge list gets.
+func (e *activeEdge) advance(y int, conv Convention) {
+	if conv == TopLeft {
+		e.step += e.dx
+		e.x = settleCentre(e.edge[0], e.edge[1], y, math.Ceil(e.step-0.5))
+		return
+	}
+	e.step = ddaSettle(e.edge, e.dx, e.dy, y, e.step)
+	e.x = math.Floor(e.edge[0].X + e.step*e.dx)
+}
+
+// ddaSettle returns the first step of the DDA along edge that falls on
+// scanline y, searching from step i.
+func ddaSettle(edge [2]Point, dx, dy float64, y int, i float64) float64 {
+	before := func(i float64) bool {
+		if dy > 0 {
+			return math.Floor(edge[0].Y+i*dy) < float64(y)
+		}
+		return math.Floor(edge[0].Y+i*dy) > float64(y)
+	}
+	for before(i) {
+		i++
+	}
+	for i > 1 && !before(i-1) {
+		i--
+	}
+	return i
+}
+
+type horizontalEdge struct {
+	y    int
+	span [2]int
+}
+
+type vertexCrossing struct {
+	y int
+	crossing
+}
+
+// activeEdgeTable is the classic scan conversion: edges are bucketed by the
+// first scanline they cross, and while the scanlines are swept top to
+// bottom only the active edges are kept. The crossings it finds are the
+// same as the ordered edge list's with either convention: crossings at
+// vertices and horizontal edges are bucketed by their scanline as well.
+type activeEdgeTable struct {
+	edges      []activeEdge
+	horizontal []horizontalEdge
+	vertices   []vertexCrossing
+	conv       Convention
+}
+
+func (table *activeEdgeTable) addEdges(edges [][2]Point) {
+	if table.conv == TopLeft {
+		table.addCentres(edges)
+		return
+	}
+	boundary(edges, func(x, y float64, dir int) {
+		table.vertices = append(table.vertices, vertexCrossing{row(y), crossing{math.Floor(x), dir}})
+	}, func(edge [2]Point) {
+		x1, x2 := int(math.Floor(edge[0].X)), int(math.Floor(edge[1].X))
+		if x1 > x2 {
+			x1, x2 = x2, x1
+		}
+		table.horizontal = append(table.horizontal, horizontalEdge{row(edge[0].Y), [2]int{x1, x2}})
+	}, func(edge [2]Point, dir int) {
+		//строки строго между концами ребра
+		yMin, yMax := row(edge[0].Y)+1, row(edge[1].Y)
+		if dir < 0 {
+			yMin, yMax = row(edge[1].Y)+1, row(edge[0].Y)
+		}
+		if yMin >= yMax {
+			return
+		}
+		dx, dy := ddaStep(edge)
+		table.edges = append(table.edges, activeEdge{yMin: yMin, yMax: yMax, edge: edge, dx: dx, dy: dy, dir: dir})
+	})
+}
+
+// addCentres adds the edges crossing scanline centres, see
+// edgeList.addCentres.
+func (table *activeEdgeTable) addCentres(edges [][2]Point) {
+	for _, edge := range edges {
+		top, bottom, yMin, yMax, dir := centreRows(edge)
+		if yMin < yMax {
+			table.edges = append(table.edges, activeEdge{yMin: yMin, yMax: yMax, edge: [2]Point{top, bottom}, dir: dir})
+		}
+	}
+}
+
+// sort orders the buckets by their first scanline.
+func (table *activeEdgeTable) sort() {
+	sort.Slice(table.edges, func(i, j int) bool {
+		return table.edges[i].yMin < table.edges[j].yMin
+	})
+	sort.Slice(table.horizontal, func(i, j int) bool {
+		return table.horizontal[i].y < table.horizontal[j].y
+	})
+	sort.Slice(table.vertices, func(i, j int) bool {
+		a, b := table.vertices[i], table.vertices[j]
+		return a.y < b.y || a.y == b.y && a.x < b.x
+	})
+}
+
+func (table *activeEdgeTable) spans(rule FillRule, span func(y, x1, x2 int)) {
+	active := []*activeEdge{}
+	crossings := []crossing{}
+	row := [][2]int{}
+	next, nextHorizontal, nextVertex := 0, 0, 0
+	y := 0
+	for next < len(table.edges) || nextHorizontal < len(table.horizontal) || nextVertex < len(table.vertices) || len(active) > 0 {
+		if len(active) == 0 {
+			//пропускаем пустые строки
+			y = math.MaxInt32
+			if next < len(table.edges) {
+				y = table.edges[next].yMin
+			}
+			if nextHorizontal < len(table.horizontal) && table.horizontal[nextHorizontal].y < y {
+				y = table.horizontal[nextHorizontal].y
+			}
+			if nextVertex < len(table.vertices) && table.vertices[nextVertex].y < y {
+				y = table.vertices[nextVertex].y
+			}
+		}
+
+		for next < len(table.edges) && table.edges[next].yMin == y {
+			active = append(active, &table.edges[next])
+			next++
+		}
+		kept := active[:0]
+		for _, e := range active {
+			if e.yMax > y {
+				e.advance(y, table.conv)
+				kept = append(kept, e)
+			}
+		}
+		active = kept
+
+		//от строки к строке порядок почти не меняется, сортировка вставками
+		for i := 1; i < len(active); i++ {
+			for j := i; j > 0 && active[j].x < active[j-1].x; j-- {
+				active[j], active[j-1] = active[j-1], active[j]
+			}
+		}
+
+		//вершины строки уже упорядочены по x, сливаем их с активными рёбрами
+		crossings = crossings[:0]
+		for _, e := range active {
+			for nextVertex < len(table.vertices) && table.vertices[nextVertex].y == y && table.vertices[nextVertex].x < e.x {
+				crossings = append(crossings, table.vertices[nextVertex].crossing)
+				nextVertex++
+			}
+			crossings = append(crossings, crossing{e.x, e.dir})
+		}
+		for nextVertex < len(table.vertices) && table.vertices[nextVertex].y == y {
+			crossings = append(crossings, table.vertices[nextVertex].crossing)
+			nextVertex++
+		}
+		row = rule.walk(crossings, table.conv, row[:0])
+		for nextHorizontal < len(table.horizontal) && table.horizontal[nextHorizontal].y == y {
+			row = append(row, table.horizontal[nextHorizontal].span)
+			nextHorizontal++
+		}
+		mergeSpans(y, row, span)
+		y++
+	}
+}
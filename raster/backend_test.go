@@ -0,0 +1,38 @@
+package raster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// starPolygon returns a star-like contour with n vertices spread around the
+// centre of a size x size image, so that most scanlines cross many edges.
+func starPolygon(n, size int) [][]Point {
+	random := rand.New(rand.NewSource(1))
+	contour := make([]Point, n)
+	c := float64(size) / 2
+	for i := range contour {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		radius := (0.2 + 0.8*random.Float64()) * c
+		contour[i] = Point{X: c + radius*math.Cos(angle), Y: c + radius*math.Sin(angle)}
+	}
+	return [][]Point{contour}
+}
+
+func benchmarkFill(b *testing.B, backend Backend) {
+	const size = 1000
+	contours := starPolygon(5000, size)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Fill(New(size, size), contours, Options{Backend: backend})
+	}
+}
+
+func BenchmarkFillOrderedEdgeList(b *testing.B) {
+	benchmarkFill(b, OrderedEdgeList)
+}
+
+func BenchmarkFillActiveEdgeTable(b *testing.B) {
+	benchmarkFill(b, ActiveEdgeTable)
+}
@@ -0,0 +1,91 @@
+package raster
+
+import "math"
+
+// Convention decides which pixels along the boundary belong to a polygon.
+type Convention int
+
+const (
+	// Inclusive fills, on every scanline, the pixels from the one the
+	// opening crossing falls in to the one the closing crossing falls in,
+	// where an edge crosses a scanline at a vertex or at the first DDA step
+	// on it. Polygons sharing an edge both cover the pixels along it. Pixels
+	// the boundary only grazes between two steps may stay empty, even when
+	// their centres are inside.
+	Inclusive Convention = iota
+	// TopLeft samples pixels at their centres and takes a centre lying
+	// exactly on the boundary only if it is on a top or left edge. A tiling
+	// of polygons then covers every pixel exactly once.
+	TopLeft
+)
+
+func (conv Convention) String() string {
+	if conv == TopLeft {
+		return "top-left"
+	}
+	return "inclusive"
+}
+
+// pixels converts the span between two crossings of a scanline into the
+// range of pixels it fills. With Inclusive a crossing is the pixel it falls
+// in, with TopLeft the first pixel whose centre is not to the left of the
+// edge. ok is false when the span holds no pixel centre.
+func (conv Convention) pixels(x1, x2 float64) (int, int, bool) {
+	if conv == TopLeft {
+		from, to := int(x1), int(x2)-1
+		return from, to, from <= to
+	}
+	return int(x1), int(x2), true
+}
+
+// centreRows orders edge top to bottom and returns the scanlines whose
+// centres it crosses, yMin up to yMax-1. An edge covers the centres in
+// [top, bottom), so a vertex is never counted twice and horizontal edges
+// cover none.
+func centreRows(edge [2]Point) (top, bottom Point, yMin, yMax, dir int) {
+	top, bottom, dir = edge[0], edge[1], 1
+	if top.Y > bottom.Y {
+		top, bottom, dir = bottom, top, -1
+	}
+	return top, bottom, int(math.Ceil(top.Y - 0.5)), int(math.Ceil(bottom.Y - 0.5)), dir
+}
+
+// addCentres records, for every edge, its exact crossing with the centre of
+// each scanline it passes. A centre on a top edge is inside, on a bottom
+// edge outside.
+func (list *edgeList) addCentres(edges [][2]Point) {
+	for _, edge := range edges {
+		top, bottom, yMin, yMax, dir := centreRows(edge)
+		if yMin >= yMax {
+			continue
+		}
+		for y := yMin; y < yMax; y++ {
+			list.crossings[y] = append(list.crossings[y], crossing{centreCrossing(top, bottom, y), dir})
+		}
+	}
+}
+
+// centreCrossing returns the first pixel of scanline y whose centre is not
+// to the left of the edge from top to bottom.
+func centreCrossing(top, bottom Point, y int) float64 {
+	cy := float64(y) + 0.5
+	return settleCentre(top, bottom, y, math.Ceil(top.X+(cy-top.Y)*(bottom.X-top.X)/(bottom.Y-top.Y)-0.5))
+}
+
+// settleCentre moves the estimated crossing px of scanline y to the first
+// pixel whose centre is not to the left of the edge. The sign of the edge
+// function is exact for vertices on a fine enough grid such as whole or
+// half pixels, so a centre lying on the edge is never put on the wrong side.
+func settleCentre(top, bottom Point, y int, px float64) float64 {
+	cy := float64(y) + 0.5
+	notLeft := func(px float64) bool {
+		return (bottom.X-top.X)*(cy-top.Y)-(bottom.Y-top.Y)*(px+0.5-top.X) <= 0
+	}
+	for !notLeft(px) {
+		px++
+	}
+	for notLeft(px - 1) {
+		px--
+	}
+	return px
+}
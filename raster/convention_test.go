@@ -0,0 +1,59 @@
+package raster
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// insideTopLeft decides whether the centre of pixel (px, y) is inside
+// contours by the top-left rule in exact integer arithmetic. Vertices must
+// lie on the half-pixel grid.
+func insideTopLeft(contours [][]Point, rule FillRule, px, y int) bool {
+	cx, cy := int64(2*px+1), int64(2*y+1)
+	counter := 0
+	for _, contour := range contours {
+		for i := range contour {
+			top, bottom, dir := contour[i], contour[(i+1)%len(contour)], 1
+			if top.Y > bottom.Y {
+				top, bottom, dir = bottom, top, -1
+			}
+			x1, y1, x2, y2 := int64(2*top.X), int64(2*top.Y), int64(2*bottom.X), int64(2*bottom.Y)
+			if cy < y1 || cy >= y2 {
+				continue
+			}
+			//ребро левее центра или проходит через него
+			if (x2-x1)*(cy-y1)-(y2-y1)*(cx-x1) <= 0 {
+				counter += rule.step(dir)
+			}
+		}
+	}
+	return rule.inside(counter)
+}
+
+func TestTopLeftCentresOnEdges(t *testing.T) {
+	const size = 40
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		contours := [][]Point{}
+		for k := 0; k < 1+random.Intn(2); k++ {
+			contour := make([]Point, 3+random.Intn(8))
+			for j := range contour {
+				contour[j] = Point{X: float64(random.Intn(2*size)) / 2, Y: float64(random.Intn(2*size)) / 2}
+			}
+			contours = append(contours, contour)
+		}
+		for _, rule := range []FillRule{EvenOdd, NonZero} {
+			for _, backend := range []Backend{OrderedEdgeList, ActiveEdgeTable} {
+				r := New(size, size)
+				Fill(r, contours, Options{Rule: rule, Backend: backend, Convention: TopLeft})
+				for y := 0; y < size; y++ {
+					for x := 0; x < size; x++ {
+						if got, want := r.Pix[r.offset(x, y)] != 0, insideTopLeft(contours, rule, x, y); got != want {
+							t.Fatalf("%v, %v, %v: pixel (%d, %d) filled %v, want %v", contours, rule, backend, x, y, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
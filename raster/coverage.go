@@ -0,0 +1,52 @@
+package raster
+
+// fillCoverage rasterises the polygon on a grid Samples times finer than r
+// and accumulates, for every pixel, how many of its Samples x Samples
+// subpixels are covered. Pixels inside the polygon end up fully covered, so
+// only the edge pixels get intermediate values and the fill stays sharp.
+// Subpixels are sampled at their centres whatever opts.Convention says:
+// the Inclusive convention would cover one subpixel too many along the
+// bottom and right edges.
+func fillCoverage(r *Raster, contours [][]Point, opts Options) {
+	n := opts.Samples
+	coverage := make([]int, r.Width*r.Height)
+	opts.Convention = TopLeft
+	scanContours(contours, float64(n), opts).spans(opts.Rule, func(sy, sx1, sx2 int) {
+		y := sy / n
+		if sy < 0 || y >= r.Height {
+			return
+		}
+		if sx1 < 0 {
+			sx1 = 0
+		}
+		if sx2 >= r.Width*n {
+			sx2 = r.Width*n - 1
+		}
+		if sx1 > sx2 {
+			return
+		}
+		for x := sx1 / n; x <= sx2/n; x++ {
+			from, to := x*n, x*n+n-1
+			if from < sx1 {
+				from = sx1
+			}
+			if to > sx2 {
+				to = sx2
+			}
+			coverage[y*r.Width+x] += to - from + 1
+		}
+	})
+
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			c := coverage[y*r.Width+x]
+			if c == 0 {
+				continue
+			}
+			v := uint8(c * 255 / (n * n))
+			if v > r.At(x, y) {
+				r.Set(x, y, v)
+			}
+		}
+	}
+}
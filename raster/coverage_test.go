@@ -0,0 +1,24 @@
+package raster
+
+import "testing"
+
+func TestCoverageIntegerSquare(t *testing.T) {
+	square := [][]Point{{{2, 2}, {6, 2}, {6, 6}, {2, 6}}}
+	for _, conv := range []Convention{Inclusive, TopLeft} {
+		for _, backend := range []Backend{OrderedEdgeList, ActiveEdgeTable} {
+			r := New(10, 10)
+			Fill(r, square, Options{Samples: 4, Backend: backend, Convention: conv})
+			for y := 0; y < r.Height; y++ {
+				for x := 0; x < r.Width; x++ {
+					want := uint8(0)
+					if x >= 2 && x < 6 && y >= 2 && y < 6 {
+						want = 255
+					}
+					if got := r.At(x, y); got != want {
+						t.Errorf("%v, %v: pixel (%d, %d) is %d, want %d", conv, backend, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
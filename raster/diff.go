@@ -0,0 +1,41 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// LoadImage reads a PNG file.
+func LoadImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+// Diff compares two images pixel by pixel and returns the number of pixels
+// that differ together with a picture of the differences: matching pixels
+// are a dimmed copy of want, differing ones are red. Images of different
+// sizes are compared over the union of their bounds.
+func Diff(got, want image.Image) (int, *image.RGBA) {
+	bounds := got.Bounds().Union(want.Bounds())
+	diff := image.NewRGBA(bounds)
+	count := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			g := color.RGBAModel.Convert(got.At(x, y)).(color.RGBA)
+			w := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+			if g != w || !(image.Point{x, y}).In(got.Bounds()) || !(image.Point{x, y}).In(want.Bounds()) {
+				count++
+				diff.Set(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+			diff.Set(x, y, color.RGBA{w.R / 4, w.G / 4, w.B / 4, 255})
+		}
+	}
+	return count, diff
+}
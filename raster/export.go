@@ -0,0 +1,74 @@
+package raster
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Image returns a top-down copy of fb. Like fb, the image is not
+// premultiplied by alpha.
+func (fb *Framebuffer) Image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, fb.Width, fb.Height))
+	for y := 0; y < fb.Height; y++ {
+		i := fb.offset(0, y)
+		copy(img.Pix[y*img.Stride:], fb.Pix[i:i+4*fb.Width])
+	}
+	return img
+}
+
+// Image returns a top-down copy of r.
+func (r *Raster) Image() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, r.Width, r.Height))
+	for y := 0; y < r.Height; y++ {
+		i := r.offset(0, y)
+		copy(img.Pix[y*img.Stride:], r.Pix[i:i+r.Width])
+	}
+	return img
+}
+
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// WritePPM writes img as a binary (P6) PPM. PPM has no alpha channel, so
+// only the colour channels are stored, as they are without alpha.
+func WritePPM(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "P6\n%d %d\n255\n", bounds.Dx(), bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			out.Write([]byte{c.R, c.G, c.B})
+		}
+	}
+	return out.Flush()
+}
+
+// SaveImage writes img to path as PNG or PPM depending on the extension.
+func SaveImage(path string, img image.Image) error {
+	write := WritePNG
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+	case ".ppm":
+		write = WritePPM
+	default:
+		return fmt.Errorf("raster: unknown image format %q", filepath.Ext(path))
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,39 @@
+package raster
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestExportTranslucent(t *testing.T) {
+	translucent := color.RGBA{255, 0, 0, 160}
+	fb := NewFramebuffer(2, 1, color.RGBA{0, 0, 0, 255})
+	fb.Set(1, 0, translucent)
+
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, fb.Image()); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < fb.Width; x++ {
+		got := color.NRGBAModel.Convert(img.At(x, 0)).(color.NRGBA)
+		want := fb.At(x, 0)
+		if got != (color.NRGBA{want.R, want.G, want.B, want.A}) {
+			t.Errorf("PNG pixel %d is %v, want %v", x, got, want)
+		}
+	}
+
+	buf.Reset()
+	if err := WritePPM(&buf, fb.Image()); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte("P6\n2 1\n255\n"), 0, 0, 0, 255, 0, 0)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("PPM is %v, want %v", buf.Bytes(), want)
+	}
+}
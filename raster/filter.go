@@ -0,0 +1,160 @@
+package raster
+
+import (
+	"errors"
+	"math"
+)
+
+// Kernel is a square convolution matrix of odd size. Weights are stored row
+// by row, top to bottom, and the weighted sum is divided by Divisor.
+type Kernel struct {
+	Name    string
+	Size    int
+	Weights []float64
+	Divisor float64
+}
+
+// NewKernel builds a kernel from size*size weights. The divisor is the sum
+// of the weights, or 1 when they sum to zero as in edge detection.
+func NewKernel(name string, size int, weights []float64) (Kernel, error) {
+	if size < 1 || size%2 == 0 {
+		return Kernel{}, errors.New("raster: kernel size must be odd")
+	}
+	if len(weights) != size*size {
+		return Kernel{}, errors.New("raster: kernel needs size*size weights")
+	}
+	divisor := 0.0
+	for _, w := range weights {
+		divisor += w
+	}
+	if divisor == 0 {
+		divisor = 1
+	}
+	return Kernel{name, size, weights, divisor}, nil
+}
+
+var (
+	Box3 = Kernel{"box 3x3", 3, []float64{
+		1, 1, 1,
+		1, 1, 1,
+		1, 1, 1,
+	}, 9}
+	Gaussian3 = Kernel{"gaussian 3x3", 3, []float64{
+		1, 2, 1,
+		2, 4, 2,
+		1, 2, 1,
+	}, 16}
+	Gaussian5 = Kernel{"gaussian 5x5", 5, []float64{
+		1, 4, 6, 4, 1,
+		4, 16, 24, 16, 4,
+		6, 24, 36, 24, 6,
+		4, 16, 24, 16, 4,
+		1, 4, 6, 4, 1,
+	}, 256}
+	Sharpen = Kernel{"sharpen", 3, []float64{
+		0, -1, 0,
+		-1, 5, -1,
+		0, -1, 0,
+	}, 1}
+	EdgeDetect = Kernel{"edge detect", 3, []float64{
+		-1, -1, -1,
+		-1, 8, -1,
+		-1, -1, -1,
+	}, 1}
+
+	// Kernels lists the predefined kernels in the order the labs cycle them.
+	Kernels = []Kernel{Gaussian3, Gaussian5, Box3, Sharpen, EdgeDetect}
+)
+
+// Border tells Convolve what lies outside the raster.
+type Border int
+
+const (
+	// Clamp repeats the nearest edge pixel.
+	Clamp Border = iota
+	// Wrap takes pixels from the opposite side.
+	Wrap
+	// Zero treats everything outside as black.
+	Zero
+)
+
+func (b Border) String() string {
+	switch b {
+	case Wrap:
+		return "wrap"
+	case Zero:
+		return "zero"
+	}
+	return "clamp"
+}
+
+// coord maps a coordinate that may lie outside [0, n) back inside it. ok is
+// false when the pixel should be read as zero.
+func (b Border) coord(i, n int) (int, bool) {
+	if i >= 0 && i < n {
+		return i, true
+	}
+	switch b {
+	case Wrap:
+		return (i%n + n) % n, true
+	case Zero:
+		return 0, false
+	}
+	if i < 0 {
+		return 0, true
+	}
+	return n - 1, true
+}
+
+// Convolve applies k to every pixel of r, reading pixels outside the raster
+// according to border.
+func Convolve(r *Raster, k Kernel, border Border) {
+	r.Pix = convolve(r.Pix, r.Width, r.Height, 1, k, border)
+}
+
+// convolve filters a bottom-up buffer with the given number of interleaved
+// channels, each channel on its own.
+func convolve(pix []uint8, width, height, channels int, k Kernel, border Border) []uint8 {
+	offset := func(x, y int) int {
+		return ((height-1-y)*width + x) * channels
+	}
+	tempPixels := make([]uint8, len(pix))
+	half := k.Size / 2
+	sum := make([]float64, channels)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			for c := range sum {
+				sum[c] = 0
+			}
+			for i := 0; i < k.Size; i++ {
+				sy, ok := border.coord(y+i-half, height)
+				if !ok {
+					continue
+				}
+				for j := 0; j < k.Size; j++ {
+					sx, ok := border.coord(x+j-half, width)
+					if !ok {
+						continue
+					}
+					w := k.Weights[i*k.Size+j]
+					for c := range sum {
+						sum[c] += w * float64(pix[offset(sx, sy)+c])
+					}
+				}
+			}
+			for c := range sum {
+				tempPixels[offset(x, y)+c] = clampByte(sum[c] / k.Divisor)
+			}
+		}
+	}
+	return tempPixels
+}
+
+func clampByte(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+// Filter3x3 is the post-filter from the lab: a weighted 3x3 average.
+func Filter3x3(r *Raster) {
+	Convolve(r, Gaussian3, Clamp)
+}
@@ -0,0 +1,76 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+)
+
+// Connectivity tells FloodFill which neighbours belong to the same region.
+type Connectivity int
+
+const (
+	FourConnected  Connectivity = 4
+	EightConnected Connectivity = 8
+)
+
+// FloodFill paints the region of same-coloured pixels around (x, y) with c,
+// blended over it like the rest of the framebuffer, and returns the number
+// of pixels painted. It is the span-based seed fill:
+// every seed is extended to the whole horizontal run it belongs to and only
+// one new seed per run is pushed for the rows above and below, on an
+// explicit stack instead of recursion, so big regions cannot overflow the
+// call stack.
+func FloodFill(fb *Framebuffer, x, y int, c color.RGBA, conn Connectivity) int {
+	if !fb.inside(x, y) {
+		return 0
+	}
+	//у всей области один цвет, значит и результат смешивания один
+	target := fb.At(x, y)
+	c = over(c, target, 255)
+	if target == c {
+		return 0
+	}
+	painted := 0
+	stack := []image.Point{{x, y}}
+	for len(stack) > 0 {
+		seed := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if fb.At(seed.X, seed.Y) != target {
+			continue
+		}
+
+		left, right := seed.X, seed.X
+		for left > 0 && fb.At(left-1, seed.Y) == target {
+			left--
+		}
+		for right < fb.Width-1 && fb.At(right+1, seed.Y) == target {
+			right++
+		}
+		for i := left; i <= right; i++ {
+			fb.Set(i, seed.Y, c)
+		}
+		painted += right - left + 1
+
+		//для восьмисвязной области соседи по диагонали тоже считаются
+		if conn == EightConnected {
+			left, right = left-1, right+1
+		}
+		for _, ny := range []int{seed.Y - 1, seed.Y + 1} {
+			if ny < 0 || ny >= fb.Height {
+				continue
+			}
+			inRun := false
+			for i := left; i <= right; i++ {
+				if fb.inside(i, ny) && fb.At(i, ny) == target {
+					if !inRun {
+						stack = append(stack, image.Point{i, ny})
+					}
+					inRun = true
+				} else {
+					inRun = false
+				}
+			}
+		}
+	}
+	return painted
+}
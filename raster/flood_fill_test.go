@@ -0,0 +1,31 @@
+package raster
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestFloodFillBlends(t *testing.T) {
+	fb := NewFramebuffer(4, 3, color.RGBA{0, 0, 0, 255})
+	fb.Set(2, 0, color.RGBA{255, 255, 255, 255})
+	fb.Set(2, 1, color.RGBA{255, 255, 255, 255})
+	fb.Set(2, 2, color.RGBA{255, 255, 255, 255})
+
+	if painted := FloodFill(fb, 0, 0, color.RGBA{255, 0, 0, 160}, FourConnected); painted != 6 {
+		t.Errorf("painted %d pixels, want 6", painted)
+	}
+	want := color.RGBA{160, 0, 0, 255}
+	for y := 0; y < fb.Height; y++ {
+		for x := 0; x < 2; x++ {
+			if got := fb.At(x, y); got != want {
+				t.Errorf("pixel (%d, %d) is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+	if got := fb.At(3, 0); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("pixel (3, 0) behind the wall is %v", got)
+	}
+	if painted := FloodFill(fb, 0, 0, want, FourConnected); painted != 0 {
+		t.Errorf("filling with the same colour painted %d pixels", painted)
+	}
+}
@@ -0,0 +1,102 @@
+package raster
+
+import "image/color"
+
+// Framebuffer is an RGBA pixel buffer with four bytes per pixel and, like
+// Raster, rows stored bottom-up for gl.DrawPixels. Colours are not
+// premultiplied by alpha.
+type Framebuffer struct {
+	Width  int
+	Height int
+	Pix    []uint8
+}
+
+// Polygon is a set of contours filled together with one colour.
+type Polygon struct {
+	Contours [][]Point
+	Color    color.RGBA
+}
+
+// NewFramebuffer returns a framebuffer of the given size filled with
+// background.
+func NewFramebuffer(width, height int, background color.RGBA) *Framebuffer {
+	fb := &Framebuffer{width, height, make([]uint8, 4*width*height)}
+	for i := 0; i < len(fb.Pix); i += 4 {
+		fb.Pix[i], fb.Pix[i+1], fb.Pix[i+2], fb.Pix[i+3] = background.R, background.G, background.B, background.A
+	}
+	return fb
+}
+
+func (fb *Framebuffer) offset(x, y int) int {
+	return 4 * ((fb.Height-1-y)*fb.Width + x)
+}
+
+func (fb *Framebuffer) inside(x, y int) bool {
+	return x >= 0 && x < fb.Width && y >= 0 && y < fb.Height
+}
+
+func (fb *Framebuffer) At(x, y int) color.RGBA {
+	if !fb.inside(x, y) {
+		return color.RGBA{}
+	}
+	i := fb.offset(x, y)
+	return color.RGBA{fb.Pix[i], fb.Pix[i+1], fb.Pix[i+2], fb.Pix[i+3]}
+}
+
+func (fb *Framebuffer) Set(x, y int, c color.RGBA) {
+	if fb.inside(x, y) {
+		i := fb.offset(x, y)
+		fb.Pix[i], fb.Pix[i+1], fb.Pix[i+2], fb.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+}
+
+// Blend draws c over the pixel at (x, y) with the "source over" operator.
+// coverage scales the alpha of c, so anti-aliased masks blend smoothly.
+func (fb *Framebuffer) Blend(x, y int, c color.RGBA, coverage uint8) {
+	if fb.inside(x, y) {
+		fb.Set(x, y, over(c, fb.At(x, y), coverage))
+	}
+}
+
+// over returns c drawn over dst with the "source over" operator, the alpha
+// of c scaled by coverage. Neither colour is premultiplied.
+func over(c, dst color.RGBA, coverage uint8) color.RGBA {
+	sa := float64(c.A) / 255 * float64(coverage) / 255
+	if sa == 0 {
+		return dst
+	}
+	da := float64(dst.A) / 255
+	outA := sa + da*(1-sa)
+	blend := func(sc, dc uint8) uint8 {
+		return clampByte((float64(sc)*sa + float64(dc)*da*(1-sa)) / outA)
+	}
+	return color.RGBA{blend(c.R, dst.R), blend(c.G, dst.G), blend(c.B, dst.B), clampByte(outA * 255)}
+}
+
+// Composite blends c over fb wherever mask is lit, using the mask value as
+// coverage.
+func (fb *Framebuffer) Composite(mask *Raster, c color.RGBA) {
+	for y := 0; y < fb.Height && y < mask.Height; y++ {
+		for x := 0; x < fb.Width && x < mask.Width; x++ {
+			if v := mask.At(x, y); v > 0 {
+				fb.Blend(x, y, c, v)
+			}
+		}
+	}
+}
+
+// FillPolygons fills every polygon with its own colour, later polygons
+// blending over earlier ones.
+func FillPolygons(fb *Framebuffer, polygons []Polygon, opts Options) {
+	mask := New(fb.Width, fb.Height)
+	for _, polygon := range polygons {
+		mask.Clear()
+		Fill(mask, polygon.Contours, opts)
+		fb.Composite(mask, polygon.Color)
+	}
+}
+
+// ConvolveRGBA applies k to each channel of fb separately.
+func ConvolveRGBA(fb *Framebuffer, k Kernel, border Border) {
+	fb.Pix = convolve(fb.Pix, fb.Width, fb.Height, 4, k, border)
+}
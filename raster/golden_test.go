@@ -0,0 +1,74 @@
+package raster
+
+import (
+	"flag"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the reference images in testdata")
+
+// goldenModes are the suffixes of the reference images rendered for every
+// polygon file and the options they are rendered with.
+var goldenModes = []struct {
+	name string
+	opts Options
+}{
+	{"evenodd", Options{Rule: EvenOdd}},
+	{"nonzero", Options{Rule: NonZero}},
+	{"evenodd.topleft", Options{Rule: EvenOdd, Convention: TopLeft}},
+	{"nonzero.topleft", Options{Rule: NonZero, Convention: TopLeft}},
+}
+
+// goldenSize is the side of the reference images.
+const goldenSize = 32
+
+// TestGolden renders every polygon file in testdata with both backends and
+// compares the result with <name>.<mode>.png next to it. On mismatch a
+// <name>.<mode>.diff.png is written. With -update the references are
+// rewritten instead from the ordered edge list.
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		ext := filepath.Ext(file)
+		if ext != ".txt" && ext != ".json" {
+			continue
+		}
+		polygons, err := LoadPolygons(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, mode := range goldenModes {
+			base := strings.TrimSuffix(file, ext) + "." + mode.name
+			if *update {
+				canvas := NewFramebuffer(goldenSize, goldenSize, color.RGBA{0, 0, 0, 255})
+				FillPolygons(canvas, polygons, mode.opts)
+				if err := SaveImage(base+".png", canvas.Image()); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := LoadImage(base + ".png")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, backend := range []Backend{OrderedEdgeList, ActiveEdgeTable} {
+				opts := mode.opts
+				opts.Backend = backend
+				canvas := NewFramebuffer(goldenSize, goldenSize, color.RGBA{0, 0, 0, 255})
+				FillPolygons(canvas, polygons, opts)
+				if count, diff := Diff(canvas.Image(), want); count != 0 {
+					t.Errorf("%s with the %v: %d pixels differ, see %s.diff.png", base, backend, count, base)
+					if err := SaveImage(base+".diff.png", diff); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+}
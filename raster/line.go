@@ -0,0 +1,171 @@
+package raster
+
+import "math"
+
+// LineAlgorithm selects how DrawSegment rasterises a segment.
+type LineAlgorithm int
+
+const (
+	DDALine LineAlgorithm = iota
+	BresenhamLine
+	WuLine
+)
+
+func (alg LineAlgorithm) String() string {
+	switch alg {
+	case BresenhamLine:
+		return "Bresenham"
+	case WuLine:
+		return "Wu"
+	}
+	return "DDA"
+}
+
+// DrawSegment draws the segment p0-p1 into r with the given algorithm.
+func DrawSegment(r *Raster, p0, p1 Point, alg LineAlgorithm) {
+	switch alg {
+	case BresenhamLine:
+		bresenham(r, round(p0.X), round(p0.Y), round(p1.X), round(p1.Y))
+	case WuLine:
+		wu(r, p0.X, p0.Y, p1.X, p1.Y)
+	default:
+		dda(r, p0, p1)
+	}
+}
+
+// DrawOutline draws the closed contour pts into r.
+func DrawOutline(r *Raster, pts []Point, alg LineAlgorithm) {
+	for i, p := range pts {
+		DrawSegment(r, p, pts[(i+1)%len(pts)], alg)
+	}
+}
+
+// Count returns the number of lit pixels in r and the sum of their values.
+func (r *Raster) Count() (int, int) {
+	lit, sum := 0, 0
+	for _, v := range r.Pix {
+		if v > 0 {
+			lit++
+			sum += int(v)
+		}
+	}
+	return lit, sum
+}
+
+func round(v float64) int {
+	return int(math.Floor(v + 0.5))
+}
+
+func dda(r *Raster, p0, p1 Point) {
+	dx := p1.X - p0.X
+	dy := p1.Y - p0.Y
+	count := math.Max(math.Abs(dx), math.Abs(dy))
+	if count == 0 {
+		r.Set(round(p0.X), round(p0.Y), 255)
+		return
+	}
+	dx /= count
+	dy /= count
+	for i := 0.0; i <= count; i++ {
+		r.Set(round(p0.X+i*dx), round(p0.Y+i*dy), 255)
+	}
+}
+
+// bresenham works only with integer arithmetic: err tracks the distance from
+// the ideal line scaled by 2*dx*dy so that no division is needed.
+func bresenham(r *Raster, x0, y0, x1, y1 int) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		r.Set(x0, y0, 255)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// plot lights a pixel with the given brightness, keeping the brighter value
+// where lines overlap.
+func plot(r *Raster, x, y int, brightness float64) {
+	v := clampByte(brightness * 255)
+	if v > r.At(x, y) {
+		r.Set(x, y, v)
+	}
+}
+
+func fpart(v float64) float64 {
+	return v - math.Floor(v)
+}
+
+// wu is Xiaolin Wu's anti-aliased line: every step lights the two pixels
+// nearest to the line, splitting the brightness by distance.
+func wu(r *Raster, x0, y0, x1, y1 float64) {
+	steep := math.Abs(y1-y0) > math.Abs(x1-x0)
+	if steep {
+		x0, y0 = y0, x0
+		x1, y1 = y1, x1
+	}
+	if x0 > x1 {
+		x0, x1 = x1, x0
+		y0, y1 = y1, y0
+	}
+	put := func(x, y int, brightness float64) {
+		if steep {
+			plot(r, y, x, brightness)
+		} else {
+			plot(r, x, y, brightness)
+		}
+	}
+
+	gradient := 1.0
+	if x1-x0 != 0 {
+		gradient = (y1 - y0) / (x1 - x0)
+	}
+
+	//первый конец отрезка
+	xEnd := math.Floor(x0 + 0.5)
+	yEnd := y0 + gradient*(xEnd-x0)
+	xGap := 1 - fpart(x0+0.5)
+	xStart := int(xEnd)
+	put(xStart, int(math.Floor(yEnd)), (1-fpart(yEnd))*xGap)
+	put(xStart, int(math.Floor(yEnd))+1, fpart(yEnd)*xGap)
+	y := yEnd + gradient
+
+	//второй конец отрезка
+	xEnd = math.Floor(x1 + 0.5)
+	yEnd = y1 + gradient*(xEnd-x1)
+	xGap = fpart(x1 + 0.5)
+	xStop := int(xEnd)
+	put(xStop, int(math.Floor(yEnd)), (1-fpart(yEnd))*xGap)
+	put(xStop, int(math.Floor(yEnd))+1, fpart(yEnd)*xGap)
+
+	for x := xStart + 1; x < xStop; x++ {
+		put(x, int(math.Floor(y)), 1-fpart(y))
+		put(x, int(math.Floor(y))+1, fpart(y))
+		y += gradient
+	}
+}
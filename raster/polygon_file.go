@@ -0,0 +1,155 @@
+package raster
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultColor is used for polygons that do not set their own colour.
+var DefaultColor = color.RGBA{255, 255, 255, 255}
+
+type polygonJSON struct {
+	Color    []uint8        `json:"color"`
+	Contours [][][2]float64 `json:"contours"`
+}
+
+type polygonFileJSON struct {
+	Polygons []polygonJSON `json:"polygons"`
+}
+
+// ReadPolygons reads polygons either as JSON
+//
+//	{"polygons": [{"color": [255, 0, 0, 255], "contours": [[[x, y], ...], ...]}]}
+//
+// or as plain text with one "x y" vertex per line, an empty line between
+// contours and a "color r g b [a]" line starting every new polygon. Lines
+// starting with # are comments.
+func ReadPolygons(in io.Reader) ([]Polygon, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return readPolygonsJSON(data)
+	}
+	return readPolygonsText(data)
+}
+
+// LoadPolygons reads a polygon file from path.
+func LoadPolygons(path string) ([]Polygon, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadPolygons(file)
+}
+
+func parseColor(values []uint8) (color.RGBA, error) {
+	switch len(values) {
+	case 0:
+		return DefaultColor, nil
+	case 3:
+		return color.RGBA{values[0], values[1], values[2], 255}, nil
+	case 4:
+		return color.RGBA{values[0], values[1], values[2], values[3]}, nil
+	}
+	return color.RGBA{}, fmt.Errorf("colour needs 3 or 4 components, got %d", len(values))
+}
+
+func readPolygonsJSON(data []byte) ([]Polygon, error) {
+	var file polygonFileJSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	polygons := []Polygon{}
+	for _, p := range file.Polygons {
+		c, err := parseColor(p.Color)
+		if err != nil {
+			return nil, fmt.Errorf("raster: %v", err)
+		}
+		polygon := Polygon{Color: c}
+		for _, contour := range p.Contours {
+			pts := make([]Point, len(contour))
+			for i, v := range contour {
+				pts[i] = Point{v[0], v[1]}
+			}
+			polygon.Contours = append(polygon.Contours, pts)
+		}
+		polygons = append(polygons, polygon)
+	}
+	return polygons, nil
+}
+
+func readPolygonsText(data []byte) ([]Polygon, error) {
+	polygons := []Polygon{}
+	polygon := Polygon{Color: DefaultColor}
+	contour := []Point{}
+
+	closeContour := func() {
+		if len(contour) > 0 {
+			polygon.Contours = append(polygon.Contours, contour)
+			contour = []Point{}
+		}
+	}
+	closePolygon := func() {
+		closeContour()
+		if len(polygon.Contours) > 0 {
+			polygons = append(polygons, polygon)
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			closeContour()
+			continue
+		}
+		if strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "color" {
+			values := []uint8{}
+			for _, f := range fields[1:] {
+				v, err := strconv.ParseUint(f, 10, 8)
+				if err != nil {
+					return nil, fmt.Errorf("raster: line %d: %v", n, err)
+				}
+				values = append(values, uint8(v))
+			}
+			c, err := parseColor(values)
+			if err != nil {
+				return nil, fmt.Errorf("raster: line %d: %v", n, err)
+			}
+			closePolygon()
+			polygon = Polygon{Color: c}
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("raster: line %d: expected \"x y\"", n)
+		}
+		x, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("raster: line %d: %v", n, err)
+		}
+		y, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("raster: line %d: %v", n, err)
+		}
+		contour = append(contour, Point{x, y})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	closePolygon()
+	return polygons, nil
+}
@@ -0,0 +1,47 @@
+package raster
+
+// Point is a vertex in window coordinates: x to the right, y down.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Raster is a single-channel pixel buffer. Rows are stored bottom-up, the
+// way gl.DrawPixels expects them, while Set and At take window coordinates.
+type Raster struct {
+	Width  int
+	Height int
+	Pix    []uint8
+}
+
+// New returns a blank raster of the given size.
+func New(width, height int) *Raster {
+	return &Raster{width, height, make([]uint8, width*height)}
+}
+
+func (r *Raster) offset(x, y int) int {
+	return (r.Height-1-y)*r.Width + x
+}
+
+func (r *Raster) inside(x, y int) bool {
+	return x >= 0 && x < r.Width && y >= 0 && y < r.Height
+}
+
+func (r *Raster) At(x, y int) uint8 {
+	if !r.inside(x, y) {
+		return 0
+	}
+	return r.Pix[r.offset(x, y)]
+}
+
+func (r *Raster) Set(x, y int, v uint8) {
+	if r.inside(x, y) {
+		r.Pix[r.offset(x, y)] = v
+	}
+}
+
+func (r *Raster) Clear() {
+	for i := range r.Pix {
+		r.Pix[i] = 0
+	}
+}
@@ -0,0 +1,369 @@
+package raster
+
+import (
+	"math"
+	"sort"
+)
+
+// FillRule decides which parts of a self-intersecting or nested polygon are
+// treated as inside.
+type FillRule int
+
+const (
+	EvenOdd FillRule = iota
+	NonZero
+)
+
+func (rule FillRule) String() string {
+	if rule == NonZero {
+		return "non-zero"
+	}
+	return "even-odd"
+}
+
+// crossing is a point where the polygon boundary crosses a scanline, kept
+// as the pixel boundary it makes, see Convention.pixels. dir is +1 for edges
+// going down the window and -1 for edges going up.
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// edgeList keeps, for every scanline, the crossings of the polygon boundary
+// with it and the horizontal edges lying on it. Horizontal edges are part of
+// the boundary and are drawn whatever the fill rule says.
+type edgeList struct {
+	crossings  map[int][]crossing
+	horizontal map[int][][2]int
+	conv       Convention
+}
+
+func newEdgeList(conv Convention) *edgeList {
+	return &edgeList{make(map[int][]crossing), make(map[int][][2]int), conv}
+}
+
+func makeEdges(pts []Point) [][2]Point {
+	edges := make([][2]Point, len(pts))
+	for i, p := range pts {
+		nextP := pts[(i+1)%len(pts)]
+		edges[i] = [2]Point{p, nextP}
+	}
+	return edges
+}
+
+func row(y float64) int {
+	return int(math.Floor(y))
+}
+
+// isExtrema compares scanlines rather than exact coordinates: an edge that
+// starts and ends on the same scanline behaves like a horizontal one.
+func isExtrema(y, y1, y2 float64) bool {
+	return (row(y) > row(y1) && row(y) > row(y2)) || (row(y) < row(y1) && row(y) < row(y2))
+}
+
+func vertexCountTwice(edges [][2]Point, i, j int) bool {
+	l := len(edges)
+	return isExtrema(
+		edges[i][j].Y,
+		edges[i][(j+1)%2].Y,
+		edges[(i-1+l)%l][j].Y)
+}
+
+func (list *edgeList) add(x, y float64, dir int) {
+	list.crossings[row(y)] = append(list.crossings[row(y)], crossing{math.Floor(x), dir})
+}
+
+func (list *edgeList) addHorizontal(edge [2]Point) {
+	x1, x2 := int(math.Floor(edge[0].X)), int(math.Floor(edge[1].X))
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	y := row(edge[0].Y)
+	list.horizontal[y] = append(list.horizontal[y], [2]int{x1, x2})
+}
+
+func direction(edge [2]Point) int {
+	if row(edge[1].Y) > row(edge[0].Y) {
+		return 1
+	}
+	if row(edge[1].Y) < row(edge[0].Y) {
+		return -1
+	}
+	return 0
+}
+
+// prevDirection returns the direction of the closest non-horizontal edge
+// before the i-th one.
+func prevDirection(edges [][2]Point, i int) int {
+	l := len(edges)
+	for k := 1; k <= l; k++ {
+		if dir := direction(edges[(i-k+l)%l]); dir != 0 {
+			return dir
+		}
+	}
+	return 0
+}
+
+// boundary walks the edges of a contour and reports the crossings the
+// ordered edge list records at its vertices, its horizontal edges and its
+// slanted edges, whose crossings with the scanlines strictly between their
+// ends are left to the caller. Local extrema are reported twice so that
+// every scanline gets an even number of crossings. Every crossing remembers
+// the direction of its edge for the non-zero winding rule.
+//
+// A run of horizontal edges is reported as is and, at its last vertex, adds
+// a crossing only when the run is an extremum (the edges around it go in
+// opposite directions). On a step of a staircase the vertex where the run
+// starts is already the only crossing the boundary needs.
+func boundary(edges [][2]Point, vertex func(x, y float64, dir int), horizontal func(edge [2]Point), slanted func(edge [2]Point, dir int)) {
+	if prevDirection(edges, 0) == 0 {
+		return
+	}
+	for i, edge := range edges {
+		dir := direction(edge)
+		if dir == 0 {
+			horizontal(edge)
+			next := direction(edges[(i+1)%len(edges)])
+			if next != 0 && next != prevDirection(edges, i) {
+				vertex(edge[1].X, edge[1].Y, next)
+			}
+			continue
+		}
+		if vertexCountTwice(edges, i, 0) {
+			vertex(edge[0].X, edge[0].Y, dir)
+		}
+		vertex(edge[1].X, edge[1].Y, dir)
+		slanted(edge, dir)
+	}
+}
+
+// ddaStep returns the step of the digital differential analyzer along a
+// slanted edge: the edge is cut into equal steps of at most one scanline.
+func ddaStep(edge [2]Point) (dx, dy float64) {
+	dy = edge[1].Y - edge[0].Y //разница между вершинами
+	dx = edge[1].X - edge[0].X
+
+	count := math.Ceil(math.Abs(dy))
+	return dx / count, dy / count //дельта отступа
+}
+
+// dda records the boundary of a contour walking every slanted edge with the
+// digital differential analyzer: a scanline between the ends of an edge gets
+// the crossing at the first step that falls on it.
+func (list *edgeList) dda(edges [][2]Point) {
+	boundary(edges, list.add, list.addHorizontal, func(edge [2]Point, dir int) {
+		dx, dy := ddaStep(edge)
+
+		checkEndFunc := func(i float64) bool {
+			if dy > 0 {
+				return edge[0].Y+i*dy < edge[1].Y
+			} else {
+				return edge[0].Y+i*dy > edge[1].Y
+			}
+		}
+
+		//шаг меньше пикселя может дважды попасть в одну строку
+		lastRow, endRow := math.Floor(edge[0].Y), math.Floor(edge[1].Y)
+		for i := float64(1); checkEndFunc(i); i++ {
+			y := edge[0].Y + i*dy
+			if math.Floor(y) == lastRow || math.Floor(y) == endRow {
+				continue
+			}
+			lastRow = math.Floor(y)
+			list.add(edge[0].X+i*dx, y, dir)
+		}
+	})
+}
+
+func drawLine(r *Raster, y, x1, x2 int) {
+	if y < 0 || y >= r.Height {
+		return
+	}
+	if x1 < 0 {
+		x1 = 0
+	}
+	if x2 >= r.Width {
+		x2 = r.Width - 1
+	}
+	for i := x1; i <= x2; i++ {
+		r.Pix[r.offset(i, y)] = 255
+	}
+}
+
+func (rule FillRule) inside(counter int) bool {
+	if rule == NonZero {
+		return counter != 0
+	}
+	return counter%2 != 0
+}
+
+func (rule FillRule) step(dir int) int {
+	if rule == NonZero {
+		return dir
+	}
+	return 1
+}
+
+// walk goes through the crossings of one scanline, sorted by x, and appends
+// to out the pixel spans that are inside according to rule. A span left open
+// at the end of the scanline is dropped, so an odd number of crossings is
+// harmless.
+func (rule FillRule) walk(row []crossing, conv Convention, out [][2]int) [][2]int {
+	counter, start := 0, 0.0
+	for _, c := range row {
+		wasInside := rule.inside(counter)
+		counter += rule.step(c.dir)
+		if !wasInside && rule.inside(counter) {
+			start = c.x
+		}
+		if wasInside && !rule.inside(counter) {
+			if x1, x2, ok := conv.pixels(start, c.x); ok {
+				out = append(out, [2]int{x1, x2})
+			}
+		}
+	}
+	return out
+}
+
+// mergeSpans reports the spans of one scanline, joining the ones that
+// overlap or touch so that no pixel is reported twice.
+func mergeSpans(y int, row [][2]int, span func(y, x1, x2 int)) {
+	if len(row) == 0 {
+		return
+	}
+	sort.Slice(row, func(i, j int) bool {
+		return row[i][0] < row[j][0]
+	})
+	current := row[0]
+	for _, s := range row[1:] {
+		if s[0] > current[1]+1 {
+			span(y, current[0], current[1])
+			current = s
+		} else if s[1] > current[1] {
+			current[1] = s[1]
+		}
+	}
+	span(y, current[0], current[1])
+}
+
+// spans reports the inside spans of every scanline merged with the
+// horizontal edges lying on it.
+func (list *edgeList) spans(rule FillRule, span func(y, x1, x2 int)) {
+	rows := make(map[int][][2]int)
+	for y, row := range list.crossings {
+		sort.Slice(row, func(i, j int) bool {
+			return row[i].x < row[j].x
+		})
+		rows[y] = rule.walk(row, list.conv, rows[y])
+	}
+	for y, horizontal := range list.horizontal {
+		rows[y] = append(rows[y], horizontal...)
+	}
+	for y, row := range rows {
+		mergeSpans(y, row, span)
+	}
+}
+
+// scanner is a scan conversion backend: it reports the spans of pixels
+// covered by the polygon it was built from.
+type scanner interface {
+	spans(rule FillRule, span func(y, x1, x2 int))
+}
+
+func fill(r *Raster, s scanner, rule FillRule) {
+	s.spans(rule, func(y, x1, x2 int) {
+		drawLine(r, y, x1, x2)
+	})
+}
+
+// FillPolygon scan-converts the closed polygon pts into r using the
+// ordered edge list algorithm and the even-odd rule.
+func FillPolygon(r *Raster, pts []Point) {
+	FillPolygonRule(r, pts, EvenOdd)
+}
+
+// FillPolygonRule is FillPolygon with an explicit fill rule.
+func FillPolygonRule(r *Raster, pts []Point, rule FillRule) {
+	FillContours(r, [][]Point{pts}, rule)
+}
+
+// FillContours fills several closed contours as one polygon: the edges of
+// all of them go into a shared edge list, so inner contours become holes or
+// islands depending on rule. Contours with fewer than three vertices are
+// skipped.
+func FillContours(r *Raster, contours [][]Point, rule FillRule) {
+	Fill(r, contours, Options{Rule: rule})
+}
+
+// Backend selects the scan conversion algorithm behind Fill.
+type Backend int
+
+const (
+	// OrderedEdgeList walks every edge with the DDA and collects the
+	// crossings of all scanlines before filling them.
+	OrderedEdgeList Backend = iota
+	// ActiveEdgeTable sweeps the scanlines top to bottom keeping only the
+	// edges that cross the current one.
+	ActiveEdgeTable
+)
+
+func (b Backend) String() string {
+	if b == ActiveEdgeTable {
+		return "active edge table"
+	}
+	return "ordered edge list"
+}
+
+// Options tune how Fill scan-converts a polygon.
+type Options struct {
+	Rule FillRule
+	// Samples is the number of subsamples per pixel side used for
+	// anti-aliasing. Values below 2 turn anti-aliasing off.
+	Samples    int
+	Backend    Backend
+	Convention Convention
+}
+
+func scaleContour(pts []Point, scale float64) []Point {
+	scaled := make([]Point, len(pts))
+	for i, p := range pts {
+		scaled[i] = Point{p.X * scale, p.Y * scale}
+	}
+	return scaled
+}
+
+// scanContours builds the backend chosen in opts for contours scaled by
+// scale. Contours with fewer than three vertices are skipped.
+func scanContours(contours [][]Point, scale float64, opts Options) scanner {
+	if opts.Backend == ActiveEdgeTable {
+		table := &activeEdgeTable{conv: opts.Convention}
+		for _, pts := range contours {
+			if len(pts) >= 3 {
+				table.addEdges(makeEdges(scaleContour(pts, scale)))
+			}
+		}
+		table.sort()
+		return table
+	}
+	list := newEdgeList(opts.Convention)
+	for _, pts := range contours {
+		if len(pts) < 3 {
+			continue
+		}
+		if opts.Convention == TopLeft {
+			list.addCentres(makeEdges(scaleContour(pts, scale)))
+		} else {
+			list.dda(makeEdges(scaleContour(pts, scale)))
+		}
+	}
+	return list
+}
+
+// Fill scan-converts contours into r as one polygon according to opts.
+func Fill(r *Raster, contours [][]Point, opts Options) {
+	if opts.Samples < 2 {
+		fill(r, scanContours(contours, 1, opts), opts.Rule)
+		return
+	}
+	fillCoverage(r, contours, opts)
+}
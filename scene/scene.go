@@ -0,0 +1,63 @@
+package scene
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// Polygon is a closed polygon of the polygon sweep lab. It is stored the
+// same way as in the polygon files of the raster package, so a scene can be
+// rendered by the headless rasteriser as is.
+type Polygon struct {
+	Color    []int          `json:"color,omitempty"`
+	Contours [][][2]float64 `json:"contours"`
+}
+
+// Window is the clip window of the clipping lab.
+type Window struct {
+	Left   float64 `json:"left"`
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+}
+
+// Scene is the input of one of the 2D labs: what the user has entered so
+// far and the stage the lab was in. Every lab uses only the fields it needs.
+// Coordinates are in pixels of a Width×Height window; a lab with another
+// window size scales them.
+type Scene struct {
+	Width    int             `json:"width,omitempty"`
+	Height   int             `json:"height,omitempty"`
+	Stage    int             `json:"stage"`
+	Polygons []Polygon       `json:"polygons,omitempty"`
+	Contours [][][2]float64  `json:"contours,omitempty"`
+	Points   [][2]float64    `json:"points,omitempty"`
+	Segments [][2][2]float64 `json:"segments,omitempty"`
+	Window   *Window         `json:"window,omitempty"`
+	// ClipPolygon replaces Window when the clipping lab clips by an
+	// arbitrary polygon.
+	ClipPolygon [][2]float64 `json:"clip_polygon,omitempty"`
+	Color       int          `json:"color,omitempty"`
+}
+
+// Load reads a scene from the JSON file at path.
+func Load(path string) (*Scene, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Scene
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save writes s to path as JSON.
+func (s *Scene) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", " ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}